@@ -57,8 +57,31 @@ type Model struct {
 	windowStart int
 	windowSize  int
 	showWelcome bool
+
+	// The distribution to convert for, picked after the welcome screen
+	targets       []converter.Target
+	targetCursor  int
+	target        converter.Target
+	pickingTarget bool
+
+	// Set when a Nix module is picked instead of a target distribution
+	nixModule converter.NixModule
+	nixPicked bool
+}
+
+// nixOutputs are listed after the target distributions. Instead of
+// converting the scripts, they write a Nix module for the selected apps
+var nixOutputs = []struct {
+	name   string
+	module converter.NixModule
+}{
+	{name: "Nix home-manager module", module: converter.HomeManagerModule},
+	{name: "NixOS module", module: converter.NixOSModule},
 }
 
+// nixModulePath is the file the Nix module is written to
+const nixModulePath = "omakub.nix"
+
 func (m Model) Init() tea.Cmd {
 	return tea.Batch(tea.EnterAltScreen, tea.ClearScreen)
 }
@@ -72,6 +95,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	if m.pickingTarget {
+		return m.updateTargetPicker(msg)
+	}
+
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -113,7 +140,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "enter":
 			// Only process enter if there are selections
 			if len(m.selected) > 0 {
-				err := runConversion(m.repoDir)
+				if m.nixPicked {
+					if err := runNixExport(m.selectedApps(), m.nixModule, nixModulePath); err != nil {
+						m.err = err
+					}
+					return m, tea.Quit
+				}
+				err := runConversion(m.repoDir, m.selectedTarget())
 				if err != nil {
 					m.err = err
 				}
@@ -128,6 +161,55 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// updateTargetPicker handles keys while the user picks the target distribution
+func (m Model) updateTargetPicker(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "ctrl+c", "q":
+		m.quitting = true
+		return m, tea.Quit
+	case "up", "k":
+		if m.targetCursor > 0 {
+			m.targetCursor--
+		}
+	case "down", "j":
+		if m.targetCursor < len(m.targets)+len(nixOutputs)-1 {
+			m.targetCursor++
+		}
+	case "enter":
+		if m.targetCursor < len(m.targets) {
+			m.target = m.targets[m.targetCursor]
+		} else {
+			m.nixModule = nixOutputs[m.targetCursor-len(m.targets)].module
+			m.nixPicked = true
+		}
+		m.pickingTarget = false
+	}
+	return m, nil
+}
+
+// selectedTarget returns the picked target distribution, Fedora by default
+func (m Model) selectedTarget() converter.Target {
+	if m.target == nil {
+		return converter.Fedora
+	}
+	return m.target
+}
+
+// selectedApps returns the selected apps in list order
+func (m Model) selectedApps() []converter.AppScript {
+	var apps []converter.AppScript
+	for i, choice := range m.choices {
+		if _, ok := m.selected[i]; ok {
+			apps = append(apps, choice)
+		}
+	}
+	return apps
+}
+
 // InitialModel returns a new model with initial state
 func InitialModel() Model {
 	repoDir := "./omakub"
@@ -157,11 +239,13 @@ func InitialModel() Model {
 	}
 
 	return Model{
-		choices:     apps,
-		selected:    make(map[int]struct{}),
-		repoDir:     repoDir,
-		showWelcome: true,
-		windowSize:  10, // Default window size
+		choices:       apps,
+		selected:      make(map[int]struct{}),
+		repoDir:       repoDir,
+		showWelcome:   true,
+		windowSize:    10, // Default window size
+		targets:       converter.Targets(),
+		pickingTarget: true,
 	}
 }
 
@@ -191,6 +275,40 @@ Press any key to continue...
 		return welcomeStyle.Render(welcome)
 	}
 
+	if m.pickingTarget {
+		s := titleStyle.Render("Which distribution should the scripts be converted for?")
+		s += "\n\n"
+		var names []string
+		for _, target := range m.targets {
+			names = append(names, target.Name())
+		}
+		for _, output := range nixOutputs {
+			names = append(names, output.name)
+		}
+		for i, name := range names {
+			cursor := " "
+			if m.targetCursor == i {
+				cursor = ">"
+			}
+
+			item := fmt.Sprintf("%s %s", cursor, name)
+
+			if m.targetCursor == i {
+				s += selectedItemStyle.Render(item)
+			} else {
+				s += itemStyle.Render(item)
+			}
+			s += "\n"
+		}
+		help := strings.Join([]string{
+			"↑/↓: navigate",
+			"enter: confirm",
+			"q: quit",
+		}, " • ")
+		s += "\n" + helpStyle.Render(help)
+		return containerStyle.Render(s)
+	}
+
 	s := titleStyle.Render("Select which apps you want to keep. It is a large list of shell scripts in the omakub directory. Work in progress.")
 	s += "\n\n"
 
@@ -227,7 +345,10 @@ Press any key to continue...
 		s += "\n"
 	}
 
-	additionalHelp := "Select the applications you wish to keep. Unselected applications will be converted to Fedora equivalents."
+	additionalHelp := fmt.Sprintf("Select the applications you wish to keep. Unselected applications will be converted to %s equivalents.", m.selectedTarget().Name())
+	if m.nixPicked {
+		additionalHelp = fmt.Sprintf("Select the applications you wish to keep. Their packages will be written to %s.", nixModulePath)
+	}
 	s += "\n" + helpStyle.Render(additionalHelp)
 
 	help := strings.Join([]string{
@@ -241,8 +362,8 @@ Press any key to continue...
 	return containerStyle.Render(s)
 }
 
-func runConversion(repoDir string) error {
-	err := converter.ReplaceUbuntuWithFedora(repoDir)
+func runConversion(repoDir string, target converter.Target) error {
+	err := converter.ReplaceUbuntuWith(repoDir, target)
 	if err != nil {
 		return fmt.Errorf("error replacing Ubuntu-specific commands: %v", err)
 	}
@@ -251,6 +372,20 @@ func runConversion(repoDir string) error {
 	return nil
 }
 
+// runNixExport writes a Nix module installing the packages of apps to path
+func runNixExport(apps []converter.AppScript, module converter.NixModule, path string) error {
+	content, err := converter.GenerateNixModule(apps, module)
+	if err != nil {
+		return fmt.Errorf("error generating the Nix module: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("error writing %s: %v", path, err)
+	}
+
+	fmt.Printf("Nix module written to %s\n", path)
+	return nil
+}
+
 func main() {
 	m := InitialModel()
 	p := tea.NewProgram(m, tea.WithAltScreen())
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 	"ubuntu-to-fedora/pkg/converter"
 
@@ -419,13 +421,64 @@ func TestRunConversion(t *testing.T) {
 
 	// Test successful conversion
 	t.Run("Successful conversion", func(t *testing.T) {
-		err := runConversion(tempDir)
+		err := runConversion(tempDir, converter.Fedora)
 		assert.NoError(t, err, "Expected no error for successful conversion")
 	})
 
 	// Test with invalid directory
 	t.Run("Invalid directory", func(t *testing.T) {
-		err := runConversion("/nonexistent/directory")
+		err := runConversion("/nonexistent/directory", converter.Fedora)
 		assert.Error(t, err, "Expected error for invalid directory")
 	})
 }
+
+func TestTargetPicker(t *testing.T) {
+	model := Model{
+		selected:      make(map[int]struct{}),
+		targets:       converter.Targets(),
+		pickingTarget: true,
+	}
+
+	view := model.View()
+	assert.Contains(t, view, "> Fedora", "The first target should be under the cursor")
+	assert.Contains(t, view, "Arch Linux", "Every target should be listed")
+
+	next, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	next, _ = next.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	result := next.(Model)
+	assert.False(t, result.pickingTarget, "Enter should confirm the target")
+	assert.Equal(t, converter.FedoraDNF5, result.selectedTarget(), "The target under the cursor should be picked")
+}
+
+func TestNixPicker(t *testing.T) {
+	model := Model{
+		selected:      make(map[int]struct{}),
+		targets:       converter.Targets(),
+		pickingTarget: true,
+	}
+	assert.Contains(t, model.View(), "Nix home-manager module", "The Nix modules should be listed after the targets")
+
+	var next tea.Model = model
+	for range converter.Targets() {
+		next, _ = next.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	}
+	next, _ = next.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	result := next.(Model)
+	assert.True(t, result.nixPicked, "The Nix module under the cursor should be picked")
+	assert.Equal(t, converter.HomeManagerModule, result.nixModule, "The home-manager module should be picked")
+}
+
+func TestRunNixExport(t *testing.T) {
+	tempDir := t.TempDir()
+	script := filepath.Join(tempDir, "tools.sh")
+	err := os.WriteFile(script, []byte("sudo apt install -y curl\n"), 0644)
+	assert.NoError(t, err, "Expected no error writing the test script")
+
+	path := filepath.Join(tempDir, "omakub.nix")
+	err = runNixExport([]converter.AppScript{{Name: "Tools", FilePath: script}}, converter.NixOSModule, path)
+	assert.NoError(t, err, "Expected no error writing the module")
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err, "Expected the module to be written")
+	assert.Contains(t, string(content), "    curl\n", "The module should install the app's packages")
+}
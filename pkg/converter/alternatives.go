@@ -0,0 +1,125 @@
+package converter
+
+import (
+	_ "embed"
+	"fmt"
+	"regexp"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+//go:embed data/alternatives.json
+var alternativesJSON []byte
+
+// alternativesCatalog holds the differences between Debian and target
+// distro alternatives. Paths maps Debian binary paths to the distro's; names
+// lists alternatives the distro does not consult, marked by an empty value.
+type alternativesCatalog struct {
+	Paths map[string]map[string]string `json:"paths"`
+	Names map[string]map[string]string `json:"names"`
+}
+
+var alternatives alternativesCatalog
+
+func init() {
+	mustUnmarshal("alternatives.json", alternativesJSON, &alternatives)
+}
+
+// debianJVMPattern matches JVM directories, which Debian suffixes with the
+// architecture and Fedora does not.
+var debianJVMPattern = regexp.MustCompile(`^(/usr/lib/jvm/[^/]+)-(?:amd64|arm64|armhf|i386)(/|$)`)
+
+// fedoraPath returns the Fedora location of a Debian binary path.
+func fedoraPath(p string) (string, bool) {
+	if entry, ok := alternatives.Paths[p]; ok && entry["fedora"] != "" {
+		return entry["fedora"], true
+	}
+	if debianJVMPattern.MatchString(p) {
+		return debianJVMPattern.ReplaceAllString(p, "${1}${2}"), true
+	}
+	return "", false
+}
+
+// rewriteUpdateAlternatives converts update-alternatives to alternatives.
+// The actions shared by both tools keep their arguments, with binary paths
+// moved to their Fedora locations.
+func (r *rewriter) rewriteUpdateAlternatives(cmd command) {
+	if r.rpm() && !r.dnf() {
+		// openSUSE ships update-alternatives itself.
+		return
+	}
+	if !r.requireDNF(cmd) {
+		return
+	}
+	for i, arg := range cmd.args {
+		switch arg.Lit() {
+		case "--get-selections", "--set-selections", "--all":
+			r.stub(cmd, fmt.Sprintf("alternatives has no %s option", arg.Lit()))
+			return
+		case "--install", "--slave":
+			if i+2 < len(cmd.args) {
+				name, _ := r.template(cmd.args[i+2])
+				if r.installedAlternatives == nil {
+					r.installedAlternatives = make(map[string]bool)
+				}
+				r.installedAlternatives[name] = true
+			}
+		case "--set", "--config", "--auto", "--display", "--list", "--query":
+			// Fedora never registers the alternatives it ignores, so
+			// selecting one fails unless the script installed it.
+			if i+1 < len(cmd.args) {
+				name, _ := r.template(cmd.args[i+1])
+				if ignoredAlternative(name) && !r.installedAlternatives[name] {
+					r.replace(cmd.call, "true")
+					r.warn(cmd.call, "removed update-alternatives %s %s: %s", arg.Lit(), name, ignoredAlternativeNote(name))
+					return
+				}
+			}
+		}
+	}
+
+	r.replace(cmd.name, "alternatives")
+	for i, arg := range cmd.args {
+		value, _ := r.template(arg)
+		switch {
+		case value == "--quiet":
+			r.removeArg(cmd.call, arg)
+		case value == "--set" || value == "--config" || value == "--auto" || value == "--display" ||
+			value == "--list" || value == "--query":
+			if value == "--list" || value == "--query" {
+				r.replace(arg, "--display")
+				r.warn(arg, "alternatives has no %s NAME; --display prints the alternative in a different format", value)
+			}
+			if i+1 < len(cmd.args) {
+				r.checkAlternativeName(cmd.args[i+1])
+			}
+		case value == "--install" || value == "--slave":
+			if i+2 < len(cmd.args) {
+				r.checkAlternativeName(cmd.args[i+2])
+			}
+		default:
+			if path, ok := fedoraPath(value); ok {
+				r.replace(arg, path)
+				r.warn(arg, "%s is %s on Fedora", value, path)
+			}
+		}
+	}
+}
+
+// checkAlternativeName flags alternatives that Fedora programs ignore.
+func (r *rewriter) checkAlternativeName(w *syntax.Word) {
+	name, _ := r.template(w)
+	if ignoredAlternative(name) {
+		r.warn(w, "%s", ignoredAlternativeNote(name))
+	}
+}
+
+// ignoredAlternative reports whether Fedora programs ignore an alternative.
+func ignoredAlternative(name string) bool {
+	entry, ok := alternatives.Names[name]
+	return ok && entry["fedora"] == ""
+}
+
+func ignoredAlternativeNote(name string) string {
+	return fmt.Sprintf("Fedora does not use the %s alternative; set the default in the desktop settings or the environment instead", name)
+}
@@ -0,0 +1,86 @@
+package converter_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAlternatives tests the translation of update-alternatives
+func TestAlternatives(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		flagged  []string
+	}{
+		{
+			name:     "Install and set",
+			input:    "sudo update-alternatives --install /usr/bin/vi vi /usr/bin/nvim 60\nsudo update-alternatives --set vi /usr/bin/nvim",
+			expected: "sudo alternatives --install /usr/bin/vi vi /usr/bin/nvim 60\nsudo alternatives --set vi /usr/bin/nvim",
+		},
+		{
+			name:     "Debian binary paths",
+			input:    "sudo update-alternatives --set vim /usr/bin/vim.basic",
+			expected: "sudo alternatives --set vim /usr/bin/vim",
+			flagged:  []string{"/usr/bin/vim.basic is /usr/bin/vim on Fedora"},
+		},
+		{
+			name:     "JVM directories",
+			input:    "sudo update-alternatives --set java /usr/lib/jvm/java-21-openjdk-amd64/bin/java",
+			expected: "sudo alternatives --set java /usr/lib/jvm/java-21-openjdk/bin/java",
+			flagged:  []string{"on Fedora"},
+		},
+		{
+			name:     "Alternatives Fedora ignores",
+			input:    "set -e\nsudo update-alternatives --set x-terminal-emulator /usr/bin/alacritty",
+			expected: "set -e\ntrue",
+			flagged:  []string{"does not use the x-terminal-emulator alternative"},
+		},
+		{
+			name: "Alternatives Fedora ignores installed by the script",
+			input: "sudo update-alternatives --install /usr/bin/x-terminal-emulator x-terminal-emulator /usr/bin/alacritty 50\n" +
+				"sudo update-alternatives --set x-terminal-emulator /usr/bin/alacritty",
+			expected: "sudo alternatives --install /usr/bin/x-terminal-emulator x-terminal-emulator /usr/bin/alacritty 50\n" +
+				"sudo alternatives --set x-terminal-emulator /usr/bin/alacritty",
+			flagged: []string{"does not use the x-terminal-emulator alternative"},
+		},
+		{
+			name:     "Listing",
+			input:    "update-alternatives --list vi",
+			expected: "alternatives --display vi",
+			flagged:  []string{"--display prints"},
+		},
+		{
+			name:     "Listing an alternative Fedora ignores",
+			input:    "update-alternatives --list editor",
+			expected: "true",
+			flagged:  []string{"removed update-alternatives --list editor", "does not use the editor alternative"},
+		},
+		{
+			name:     "Selections",
+			input:    "update-alternatives --get-selections",
+			expected: "{ echo 'alternatives has no --get-selections option' >&2; false; }",
+			flagged:  []string{"--get-selections"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converted, report := convertScript(t, tt.input)
+			assert.Equal(t, tt.expected, converted, "update-alternatives should become alternatives")
+
+			var messages []string
+			for _, d := range report.Diagnostics {
+				messages = append(messages, d.Message)
+			}
+			for _, want := range tt.flagged {
+				assert.Contains(t, strings.Join(messages, "\n"), want, "Expected a diagnostic")
+			}
+			if len(tt.flagged) == 0 {
+				assert.Empty(t, messages, "No diagnostics expected")
+			}
+		})
+	}
+}
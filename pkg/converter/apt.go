@@ -0,0 +1,424 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// aptValueOptions are apt options whose value is passed as the next argument.
+var aptValueOptions = map[string]bool{
+	"-o":               true,
+	"--option":         true,
+	"-t":               true,
+	"--target-release": true,
+	"-c":               true,
+	"--config-file":    true,
+}
+
+// aptPackageSubcommands take package names as operands.
+var aptPackageSubcommands = map[string]bool{
+	"install":    true,
+	"reinstall":  true,
+	"remove":     true,
+	"purge":      true,
+	"autoremove": true,
+	"show":       true,
+	"list":       true,
+	"depends":    true,
+	"rdepends":   true,
+}
+
+// aptOption is a single option of an apt invocation. value is set for
+// options that take their value as a separate argument.
+type aptOption struct {
+	flag  *syntax.Word
+	value *syntax.Word
+}
+
+// aptInvocation is an apt or apt-get command split into its subcommand,
+// options and operands.
+type aptInvocation struct {
+	command
+	subcommand *syntax.Word
+	options    []aptOption
+	operands   []*syntax.Word
+}
+
+func parseApt(cmd command) aptInvocation {
+	inv := aptInvocation{command: cmd}
+	for i := 0; i < len(cmd.args); i++ {
+		arg := cmd.args[i]
+		lit := arg.Lit()
+		switch {
+		case strings.HasPrefix(lit, "-"):
+			opt := aptOption{flag: arg}
+			if aptValueOptions[lit] && i+1 < len(cmd.args) {
+				i++
+				opt.value = cmd.args[i]
+			}
+			inv.options = append(inv.options, opt)
+		case inv.subcommand == nil:
+			inv.subcommand = arg
+		default:
+			inv.operands = append(inv.operands, arg)
+		}
+	}
+	return inv
+}
+
+func (inv aptInvocation) sub() string {
+	if inv.subcommand == nil {
+		return ""
+	}
+	return inv.subcommand.Lit()
+}
+
+func (r *rewriter) rewriteApt(cmd command) {
+	inv := parseApt(cmd)
+	if inv.subcommand != nil {
+		spelling, ok := r.target.Subcommand("apt", inv.sub())
+		switch {
+		case !ok:
+			r.stub(cmd, fmt.Sprintf("cannot convert %s %s for %s", cmd.name.Lit(), inv.sub(), r.target.Name()))
+			return
+		case spelling == "":
+			r.replace(cmd.call, "true")
+			r.warn(cmd.call, "removed %s %s: %s has no equivalent", cmd.name.Lit(), inv.sub(), r.target.PackageManager())
+			return
+		case spelling != inv.sub():
+			r.replace(inv.subcommand, spelling)
+		}
+	}
+	if inv.sub() == "install" && len(inv.operands) == 0 {
+		// Without packages, apt install only repairs broken dependencies,
+		// as with -f, and the package managers reject an empty install.
+		r.replace(cmd.call, "true")
+		r.warn(cmd.call, "removed %s install without packages: %s has no equivalent", cmd.name.Lit(), r.target.PackageManager())
+		return
+	}
+	r.replace(cmd.name, r.target.PackageManager())
+
+	for _, opt := range inv.options {
+		r.translateAptOption(inv, opt)
+	}
+	if inv.sub() == "install" {
+		inv = r.installGroups(inv)
+		if len(inv.operands) == 0 {
+			return
+		}
+	}
+	if aptPackageSubcommands[inv.sub()] {
+		r.translatePackages(inv)
+	}
+}
+
+// translateAptOption rewrites a single apt option to the target's
+// spelling. Clusters of short options such as -yqq are translated letter
+// by letter. Options the package manager does not support are removed and
+// reported.
+func (r *rewriter) translateAptOption(inv aptInvocation, opt aptOption) {
+	lit := opt.flag.Lit()
+	flag := lit
+	if i := strings.Index(lit, "="); i > 0 {
+		flag = lit[:i]
+	}
+
+	var flags, dropped []string
+	implied := false
+	if spelling, ok := r.target.Option(flag); ok {
+		if spelling != "" {
+			flags = append(flags, spelling)
+		}
+		implied = r.impliedOption(flag)
+	} else if isShortCluster(lit) {
+		for _, c := range lit[1:] {
+			short := "-" + string(c)
+			spelling, _ := r.target.Option(short)
+			switch {
+			case spelling == "" && r.impliedOption(short):
+			case spelling == "":
+				dropped = append(dropped, short)
+			case !containsString(flags, spelling):
+				flags = append(flags, spelling)
+			}
+		}
+		implied = len(dropped) == 0
+	}
+
+	if len(flags) == 0 {
+		r.removeArg(inv.call, opt.flag)
+		if opt.value != nil {
+			r.removeArg(inv.call, opt.value)
+		}
+		if !implied {
+			r.warn(opt.flag, "dropped apt option %s: %s has no equivalent", r.optionText(opt), r.target.PackageManager())
+		}
+		return
+	}
+	if len(dropped) > 0 {
+		r.warn(opt.flag, "dropped apt option %s from %s: %s has no equivalent", strings.Join(dropped, " "), lit, r.target.PackageManager())
+	}
+	if r.globalOption(inv, opt, flags) {
+		return
+	}
+	if joined := strings.Join(flags, " "); joined != lit {
+		r.replace(opt.flag, joined)
+	}
+}
+
+// globalOption moves options the package manager only accepts before the
+// subcommand there, and reports whether opt was moved.
+func (r *rewriter) globalOption(inv aptInvocation, opt aptOption, flags []string) bool {
+	target, ok := r.target.(globalOptionTarget)
+	if !ok || inv.subcommand == nil || opt.flag.Pos().Offset() < inv.subcommand.Pos().Offset() {
+		return false
+	}
+	for _, flag := range flags {
+		if !target.GlobalOption(flag) {
+			return false
+		}
+	}
+	r.removeArg(inv.call, opt.flag)
+	pos := inv.subcommand.Pos().Offset()
+	r.edits = append(r.edits, edit{start: pos, end: pos, text: strings.Join(flags, " ") + " "})
+	return true
+}
+
+// isShortCluster reports whether lit is a group of single-letter options.
+func isShortCluster(lit string) bool {
+	if len(lit) < 3 || lit[0] != '-' || lit[1] == '-' {
+		return false
+	}
+	for _, c := range lit[1:] {
+		if c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *rewriter) optionText(opt aptOption) string {
+	if opt.value == nil {
+		return r.text(opt.flag)
+	}
+	return r.text(opt.flag) + " " + r.text(opt.value)
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// translatePackages rewrites each package operand to its name on the
+// target. Packages without a known mapping are kept and recorded in the
+// report, and packages the target's release does not provide are left out,
+// as they would fail the whole transaction. Installs and removals of packages prefixed with another tool,
+// such as AUR packages, move to a command of their own, and optional
+// repositories the packages come from are enabled first.
+func (r *rewriter) translatePackages(inv aptInvocation) {
+	install := inv.sub() == "install"
+	separate := install || inv.sub() == "remove" || inv.sub() == "purge"
+	var dropped, moved, missing []*syntax.Word
+	var others, repos []string
+	for _, operand := range inv.operands {
+		if file, _ := r.template(operand); strings.HasSuffix(file, ".deb") {
+			pkg, ok := r.debReplacement(inv.command, operand)
+			switch {
+			case !ok:
+				return
+			case separatePackage(pkg):
+				others = append(others, pkg)
+				moved = append(moved, operand)
+			default:
+				r.replace(operand, pkg)
+			}
+			continue
+		}
+
+		if pin, drop := r.translatePin(operand); pin {
+			if drop {
+				dropped = append(dropped, operand)
+			}
+			continue
+		}
+
+		name := operand.Lit()
+		if name == "" || strings.ContainsAny(name, "/=*") {
+			continue
+		}
+
+		names, ok := r.translatePackage(name)
+		if install && r.dnf() && containsString(names, r.grammar().automaticPackage) {
+			r.dnfAutomaticInstalled = true
+		}
+		var official []string
+		for _, n := range names {
+			switch {
+			case !separatePackage(n):
+				official = append(official, n)
+			case separate:
+				others = append(others, n)
+			default:
+				official = append(official, packageName(n))
+			}
+		}
+		if install && ok {
+			repos = append(repos, r.packageRepos(name)...)
+		}
+		switch {
+		case !ok && !packageProvided(r.target, name):
+			r.unavailable(operand, name)
+			dropped = append(dropped, operand)
+			missing = append(missing, operand)
+		case !ok:
+			r.unmapped(name)
+		case len(names) == 0:
+			dropped = append(dropped, operand)
+		case len(official) == 0:
+			moved = append(moved, operand)
+		case strings.Join(official, " ") != name:
+			r.replace(operand, strings.Join(official, " "))
+		}
+	}
+
+	if len(missing) > 0 && len(dropped) == len(inv.operands) {
+		r.stub(inv.command, fmt.Sprintf("%s not available for %s", r.wordsText(missing), r.target.Name()))
+		return
+	}
+	if len(dropped) > 0 && len(dropped) == len(inv.operands) {
+		r.replace(inv.call, "true")
+		r.warn(inv.call, "removed %s: no packages are needed on %s", r.wordsText(dropped), r.target.Name())
+		return
+	}
+	if enable, ok := r.enableRepos(r.sudoText(inv.command), repos); ok {
+		start := inv.call.Pos().Offset()
+		r.edits = append(r.edits, edit{start: start, end: start, text: enable + " && "})
+	}
+	if install && len(dropped)+len(moved) < len(inv.operands) {
+		if r.batchInstall(inv, append(dropped, moved...), others) {
+			return
+		}
+		r.noteInstall(inv.call)
+	}
+	if len(others) > 0 {
+		text := r.target.Install(r.sudoText(inv.command), others)
+		if !install {
+			text = r.target.Remove(r.sudoText(inv.command), others)
+		}
+		if len(dropped)+len(moved) == len(inv.operands) {
+			r.replace(inv.call, text)
+			return
+		}
+		end := inv.call.End().Offset()
+		r.edits = append(r.edits, edit{start: end, end: end, text: " && " + text})
+	}
+	for _, word := range append(dropped, moved...) {
+		r.removeArg(inv.call, word)
+	}
+}
+
+// batchedInstall is an install whose system packages are merged into the
+// batch of the script. others installs its remaining packages, such as
+// flatpaks.
+type batchedInstall struct {
+	call   *syntax.CallExpr
+	sudo   string
+	pkgs   []*syntax.Word
+	others string
+}
+
+// batchInstall adds an install of system packages, except the operands
+// left out, to the batch of a batchInstallTarget, and reports whether it
+// was added. Only installs that run unconditionally and whose options all
+// go away are batched; the others keep a command of their own.
+func (r *rewriter) batchInstall(inv aptInvocation, left []*syntax.Word, others []string) bool {
+	if _, ok := r.target.(batchInstallTarget); !ok || !r.topLevelCalls[inv.call] {
+		return false
+	}
+	for _, opt := range inv.options {
+		if !r.droppedOption(opt.flag.Lit()) {
+			return false
+		}
+	}
+	b := batchedInstall{call: inv.call, sudo: r.sudoText(inv.command)}
+	for _, operand := range inv.operands {
+		if !containsWord(left, operand) {
+			b.pkgs = append(b.pkgs, operand)
+		}
+	}
+	if len(others) > 0 {
+		b.others = r.target.Install(b.sudo, others)
+	}
+	r.installBatch = append(r.installBatch, b)
+	return true
+}
+
+// droppedOption reports whether the target drops the apt option flag, or
+// every letter of a cluster of short options.
+func (r *rewriter) droppedOption(flag string) bool {
+	if op, ok := r.target.Option(flag); ok {
+		return op == ""
+	}
+	if !isShortCluster(flag) {
+		return false
+	}
+	for _, c := range flag[1:] {
+		if op, ok := r.target.Option("-" + string(c)); !ok || op != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// finishInstalls merges the batched installs into the last of them, which
+// runs once every repository the script sets up is enabled, and reports
+// the target's note on installs there, or else on the first install.
+func (r *rewriter) finishInstalls() {
+	if len(r.installBatch) > 0 {
+		var pkgs []string
+		for _, b := range r.installBatch {
+			for _, w := range b.pkgs {
+				for _, pkg := range strings.Fields(string(r.applyRange(w.Pos().Offset(), w.End().Offset()))) {
+					if !containsString(pkgs, pkg) {
+						pkgs = append(pkgs, pkg)
+					}
+				}
+			}
+		}
+		last := len(r.installBatch) - 1
+		for i, b := range r.installBatch {
+			var parts []string
+			if i == last {
+				parts = append(parts, r.target.Install(b.sudo, pkgs))
+			}
+			if b.others != "" {
+				parts = append(parts, b.others)
+			}
+			if len(parts) == 0 {
+				parts = append(parts, "true")
+			}
+			r.replace(b.call, strings.Join(parts, " && "))
+		}
+		r.installNoteAt = r.installBatch[last].call
+		if last > 0 {
+			r.warn(r.installNoteAt, "merged %d %s installs into this one", last+1, r.target.PackageManager())
+		}
+	}
+	if t, ok := r.target.(installNoteTarget); ok && r.installNoteAt != nil {
+		r.warn(r.installNoteAt, "%s", t.installNote())
+	}
+}
+
+func (r *rewriter) wordsText(words []*syntax.Word) string {
+	texts := make([]string, len(words))
+	for i, w := range words {
+		texts[i] = r.text(w)
+	}
+	return strings.Join(texts, " ")
+}
@@ -0,0 +1,66 @@
+package converter
+
+import (
+	"fmt"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// aptQuerySubcommands print package information that scripts may parse.
+var aptQuerySubcommands = map[string]bool{
+	"list":     true,
+	"show":     true,
+	"showpkg":  true,
+	"search":   true,
+	"policy":   true,
+	"madison":  true,
+	"depends":  true,
+	"rdepends": true,
+	"pkgnames": true,
+}
+
+// rewriteAptCache converts apt-cache queries to the target's package
+// manager.
+func (r *rewriter) rewriteAptCache(cmd command) {
+	inv := parseApt(cmd)
+	sub := inv.sub()
+	if sub == "policy" && len(inv.operands) == 0 {
+		sub = "policy-repos"
+	}
+	spelling, ok := r.target.Subcommand("apt-cache", sub)
+	if !ok || spelling == "" {
+		r.stub(cmd, fmt.Sprintf("cannot convert apt-cache %s", r.wordsText(cmd.args)))
+		return
+	}
+
+	r.replace(cmd.name, r.target.PackageManager())
+	r.replace(inv.subcommand, spelling)
+	for _, opt := range inv.options {
+		r.translateAptOption(inv, opt)
+	}
+	if sub != "search" && sub != "pkgnames" {
+		r.translatePackages(inv)
+	}
+}
+
+// flagAptQueryPipeline warns about apt and apt-cache queries whose output
+// is parsed by the rest of a pipeline, since dnf lays out its output
+// differently. It never rewrites stmt.
+func (r *rewriter) flagAptQueryPipeline(stmt *syntax.Stmt) bool {
+	stages := pipeline(stmt)
+	if len(stages) < 2 {
+		return false
+	}
+	first, ok := stmtCommand(stages[0])
+	if !ok {
+		return false
+	}
+	switch name := first.name.Lit(); name {
+	case "apt", "apt-get", "apt-cache":
+		if sub := parseApt(first).sub(); aptQuerySubcommands[sub] {
+			r.flagPipeline(stmt, "output of %s %s is parsed by a pipeline; %s output is formatted differently, check it by hand",
+				name, sub, r.target.PackageManager())
+		}
+	}
+	return false
+}
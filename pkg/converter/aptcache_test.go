@@ -0,0 +1,67 @@
+package converter_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAptQueries tests the translation of apt-cache and apt query subcommands
+func TestAptQueries(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		flagged  bool
+	}{
+		{
+			name:     "apt-cache search and show",
+			input:    "apt-cache search --names-only ripgrep\napt-cache show libssl-dev",
+			expected: "dnf search ripgrep\ndnf info openssl-devel",
+		},
+		{
+			name:     "apt-cache policy",
+			input:    "apt-cache policy\napt-cache policy git",
+			expected: "dnf repolist\ndnf list --showduplicates git",
+		},
+		{
+			name:     "apt-cache madison and depends",
+			input:    "apt-cache madison docker-ce\napt-cache rdepends --installed build-essential",
+			expected: "dnf repoquery --showduplicates --queryformat '%{name} | %{evr} | %{repoid}' docker-ce\ndnf repoquery --whatrequires --installed gcc gcc-c++ make",
+		},
+		{
+			name:     "apt show, list and purge",
+			input:    "apt show gnupg\napt list --upgradable\nsudo apt-get purge -y fd-find\nsudo apt full-upgrade -y",
+			expected: "dnf info gnupg2\ndnf list --upgrades\nsudo dnf remove -y fd-find\nsudo dnf upgrade -y",
+		},
+		{
+			name:     "Parsed output is flagged",
+			input:    "apt list --installed 2>/dev/null | grep -c '^linux-image'",
+			expected: "dnf list --installed 2>/dev/null | grep -c '^linux-image'",
+			flagged:  true,
+		},
+		{
+			name:     "apt-cache pipeline is flagged",
+			input:    "apt-cache policy docker-ce | grep Candidate | awk '{print $2}'",
+			expected: "dnf list --showduplicates docker-ce | grep Candidate | awk '{print $2}'",
+			flagged:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converted, report := convertScript(t, tt.input)
+			assert.Equal(t, tt.expected, converted, "The query should be translated to dnf")
+
+			flags := 0
+			for _, d := range report.Diagnostics {
+				if strings.Contains(d.Message, "parsed by a pipeline") {
+					flags++
+				}
+			}
+			assert.Equal(t, tt.flagged, flags > 0, "Pipeline flag mismatch")
+			assert.LessOrEqual(t, flags, 1, "Expected the pipeline to be flagged once")
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// rewriteAptMark converts apt-mark. Holds become dnf versionlock entries,
+// and the first of them installs the versionlock plugin where it is one.
+func (r *rewriter) rewriteAptMark(cmd command) {
+	if len(cmd.args) == 0 || !r.requireDNF(cmd) {
+		return
+	}
+	sub := cmd.args[0].Lit()
+	operands := cmd.args[1:]
+	prefix := r.prefixText(cmd)
+	if prefix == "" {
+		prefix = "sudo "
+	}
+
+	switch sub {
+	case "hold", "unhold":
+		action := "add"
+		if sub == "unhold" {
+			action = "delete"
+		}
+		text := prefix + r.target.PackageManager() + " versionlock " + action + " " + r.queryPackages(operands)
+		if plugin := r.grammar().versionlockPlugin; plugin != "" && !r.versionlockInstalled {
+			r.versionlockInstalled = true
+			text = prefix + plugin + " && " + text
+		}
+		r.replace(cmd.call, text)
+	case "showhold":
+		r.replaceCommand(cmd, r.target.PackageManager()+" versionlock list", false)
+		r.warn(cmd.call, "%s versionlock list prints name-epoch:version-release.arch entries, not package names", r.target.PackageManager())
+	case "showmanual":
+		r.replaceCommand(cmd, r.grammar().userInstalled, false)
+	case "manual":
+		r.replaceCommand(cmd, r.grammar().markUser+" "+r.queryPackages(operands), true)
+	case "auto":
+		r.replaceCommand(cmd, r.grammar().markDependency+" "+r.queryPackages(operands), true)
+	default:
+		r.stub(cmd, fmt.Sprintf("cannot convert apt-mark %s", strings.TrimSpace(r.wordsText(cmd.args))))
+	}
+}
@@ -0,0 +1,207 @@
+package converter
+
+import (
+	"regexp"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+var archTokenPattern = regexp.MustCompile(`(^|[^A-Za-z0-9])(amd64|arm64|armhf|i386)($|[^A-Za-z0-9])`)
+
+// archiveSuffixes mark downloads of distro independent archives, whose
+// upstream names keep the Debian architecture spelling.
+var archiveSuffixes = []string{".tar.gz", ".tgz", ".tar.xz", ".tar.bz2", ".tar.zst", ".zip", ".deb"}
+
+// assignment is a shell variable assignment that may feed a download URL.
+type assignment struct {
+	value *syntax.Word
+	refs  []string
+}
+
+func isArchiveURL(u string) bool {
+	u = strings.SplitN(u, "?", 2)[0]
+	for _, suffix := range archiveSuffixes {
+		if strings.HasSuffix(u, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// paramRefs returns the names of the variables w expands.
+func paramRefs(w *syntax.Word) []string {
+	var refs []string
+	syntax.Walk(w, func(node syntax.Node) bool {
+		if p, ok := node.(*syntax.ParamExp); ok && p.Param != nil {
+			refs = append(refs, p.Param.Value)
+		}
+		return true
+	})
+	return refs
+}
+
+// trackAssign records an assignment so its value can be rewritten if the
+// variable turns out to feed a download URL.
+func (r *rewriter) trackAssign(as *syntax.Assign) {
+	if as.Name == nil || as.Value == nil {
+		return
+	}
+	if r.assigns == nil {
+		r.assigns = make(map[string][]assignment)
+	}
+	r.assigns[as.Name.Value] = append(r.assigns[as.Name.Value], assignment{
+		value: as.Value,
+		refs:  paramRefs(as.Value),
+	})
+}
+
+// rewriteDownloadArch rewrites architecture names in the URL of a curl or
+// wget command and remembers the variables its operands expand, since a
+// URL may be held entirely in a variable.
+func (r *rewriter) rewriteDownloadArch(cmd command) {
+	urlWord, _, ok := r.downloadURL(cmd)
+	if ok {
+		if u, _ := r.template(urlWord); isArchiveURL(u) {
+			r.markArchiveArches(urlWord)
+			if r.archiveVars == nil {
+				r.archiveVars = make(map[string]bool)
+			}
+			for _, ref := range paramRefs(urlWord) {
+				r.archiveVars[ref] = true
+			}
+			return
+		}
+		r.replaceArchTokens(urlWord, "download URL")
+	}
+	if r.archVars == nil {
+		r.archVars = make(map[string]bool)
+	}
+	output := r.outputWord(cmd)
+	for _, arg := range cmd.args {
+		if arg == output || strings.HasPrefix(arg.Lit(), "-") {
+			continue
+		}
+		for _, ref := range paramRefs(arg) {
+			r.archVars[ref] = true
+		}
+	}
+}
+
+// finishArchVars rewrites architecture names assigned to variables that
+// feed download URLs, directly or through other variables, and then the
+// dpkg --print-architecture commands.
+func (r *rewriter) finishArchVars() {
+	var archive []string
+	for name := range r.archiveVars {
+		archive = append(archive, name)
+	}
+	queue := make([]string, 0, len(r.archVars))
+	for name := range r.archVars {
+		queue = append(queue, name)
+	}
+	seen := make(map[string]bool)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		for _, as := range r.assigns[name] {
+			if value, _ := r.template(as.value); isArchiveURL(value) {
+				r.markArchiveArches(as.value)
+				archive = append(archive, as.refs...)
+				continue
+			}
+			r.replaceArchTokens(as.value, "variable "+name)
+			queue = append(queue, as.refs...)
+		}
+	}
+
+	seen = make(map[string]bool)
+	for len(archive) > 0 {
+		name := archive[0]
+		archive = archive[1:]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		for _, as := range r.assigns[name] {
+			r.markArchiveArches(as.value)
+			archive = append(archive, as.refs...)
+		}
+	}
+
+	for _, cmd := range r.printArches {
+		if r.archiveArches[cmd.call] {
+			r.replaceCommand(cmd, debianArchCommand, false)
+			r.warn(cmd.call, "dpkg --print-architecture feeds an archive URL; mapped uname -m to the amd64/arm64 names the archive uses")
+			continue
+		}
+		r.replaceCommand(cmd, "uname -m", false)
+		r.warn(cmd.call, "dpkg --print-architecture prints amd64/arm64, uname -m prints x86_64/aarch64")
+	}
+}
+
+// debianArchCommand prints the Debian name of the machine's architecture,
+// which upstream archives are named after.
+const debianArchCommand = "uname -m | sed -e s/x86_64/amd64/ -e s/aarch64/arm64/"
+
+// markArchiveArches records the dpkg --print-architecture commands
+// expanded in w, a word that ends up in an archive URL.
+func (r *rewriter) markArchiveArches(w *syntax.Word) {
+	syntax.Walk(w, func(node syntax.Node) bool {
+		call, ok := node.(*syntax.CallExpr)
+		if !ok {
+			return true
+		}
+		if cmd, ok := splitCommand(call); ok && cmd.name.Lit() == "dpkg" && hasArg(cmd, "--print-architecture") {
+			if r.archiveArches == nil {
+				r.archiveArches = make(map[*syntax.CallExpr]bool)
+			}
+			r.archiveArches[call] = true
+		}
+		return true
+	})
+}
+
+// replaceArchTokens rewrites Debian architecture names in the literal
+// parts of w, leaving expansions alone, and reports each substitution.
+func (r *rewriter) replaceArchTokens(w *syntax.Word, what string) {
+	var visit func(parts []syntax.WordPart)
+	visit = func(parts []syntax.WordPart) {
+		for _, part := range parts {
+			switch part := part.(type) {
+			case *syntax.Lit:
+				if value, ok := r.targetArches(part, part.Value, what); ok {
+					r.replace(part, value)
+				}
+			case *syntax.SglQuoted:
+				if value, ok := r.targetArches(part, part.Value, what); ok {
+					r.replace(part, "'"+value+"'")
+				}
+			case *syntax.DblQuoted:
+				visit(part.Parts)
+			}
+		}
+	}
+	visit(w.Parts)
+}
+
+// targetArches replaces Debian architecture names in value with the
+// target's names for them.
+func (r *rewriter) targetArches(at syntax.Node, value, what string) (string, bool) {
+	changed := false
+	value = archTokenPattern.ReplaceAllStringFunc(value, func(match string) string {
+		m := archTokenPattern.FindStringSubmatch(match)
+		arch, ok := r.target.Arch(m[2])
+		if !ok {
+			return match
+		}
+		r.warn(at, "replaced %s with %s in %s", m[2], arch, what)
+		changed = true
+		return m[1] + arch + m[3]
+	})
+	return value, changed
+}
@@ -0,0 +1,80 @@
+package converter_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestArchitectureNames tests the translation of Debian architecture names
+// in download URLs and the variables that feed them
+func TestArchitectureNames(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expected      string
+		substitutions int
+	}{
+		{
+			name:          "Token in a download URL",
+			input:         "curl -fsSLo tool.rpm https://example.com/releases/latest/amd64/tool",
+			expected:      "curl -fsSLo tool.rpm https://example.com/releases/latest/x86_64/tool",
+			substitutions: 1,
+		},
+		{
+			name:          "Variable feeding a URL",
+			input:         "ARCH=arm64\nwget \"https://example.com/tool-${ARCH}.rpm\"",
+			expected:      "ARCH=aarch64\nwget \"https://example.com/tool-${ARCH}.rpm\"",
+			substitutions: 1,
+		},
+		{
+			name: "Variables chained through a URL variable",
+			input: "case $(uname -m) in\n  x86_64) ARCH=amd64 ;;\n  aarch64) ARCH=arm64 ;;\nesac\n" +
+				"URL=\"https://example.com/$ARCH/tool.rpm\"\ncurl -LO \"$URL\"",
+			expected: "case $(uname -m) in\n  x86_64) ARCH=x86_64 ;;\n  aarch64) ARCH=aarch64 ;;\nesac\n" +
+				"URL=\"https://example.com/$ARCH/tool.rpm\"\ncurl -LO \"$URL\"",
+			substitutions: 2,
+		},
+		{
+			name:     "dpkg architecture in a URL",
+			input:    "curl -LO \"https://example.com/tool-$(dpkg --print-architecture).rpm\"",
+			expected: "curl -LO \"https://example.com/tool-$(uname -m).rpm\"",
+		},
+		{
+			name:     "Archives keep upstream names",
+			input:    "ARCH=amd64\ncurl -LO \"https://go.dev/dl/go1.23.linux-${ARCH}.tar.gz\"",
+			expected: "ARCH=amd64\ncurl -LO \"https://go.dev/dl/go1.23.linux-${ARCH}.tar.gz\"",
+		},
+		{
+			name:     "dpkg architecture in an archive URL",
+			input:    "ARCH=$(dpkg --print-architecture)\ncurl -Lo z.tar.gz \"https://example.com/z-${ARCH}.tar.gz\"",
+			expected: "ARCH=$(uname -m | sed -e s/x86_64/amd64/ -e s/aarch64/arm64/)\ncurl -Lo z.tar.gz \"https://example.com/z-${ARCH}.tar.gz\"",
+		},
+		{
+			name:     "dpkg architecture through a URL variable",
+			input:    "URL=\"https://example.com/z-$(dpkg --print-architecture).tar.gz\"\nwget \"$URL\"",
+			expected: "URL=\"https://example.com/z-$(uname -m | sed -e s/x86_64/amd64/ -e s/aarch64/arm64/).tar.gz\"\nwget \"$URL\"",
+		},
+		{
+			name:     "Unrelated variables are left alone",
+			input:    "PLATFORM=amd64\necho \"$PLATFORM\"",
+			expected: "PLATFORM=amd64\necho \"$PLATFORM\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converted, report := convertScript(t, tt.input)
+			assert.Equal(t, tt.expected, converted, "Architecture names should use the RPM spelling")
+
+			substitutions := 0
+			for _, d := range report.Diagnostics {
+				if strings.HasPrefix(d.Message, "replaced ") {
+					substitutions++
+				}
+			}
+			assert.Equal(t, tt.substitutions, substitutions, "Each substitution should be reported")
+		})
+	}
+}
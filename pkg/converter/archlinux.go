@@ -0,0 +1,208 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ArchLinux converts scripts for Arch Linux. Packages come from the
+// official repositories through pacman, and software that is only in the
+// Arch User Repository is built with the yay helper.
+var ArchLinux Target = archLinux{}
+
+type archLinux struct{}
+
+// aurPrefix marks catalog package names that are built from the AUR.
+const aurPrefix = "aur/"
+
+// yaySetup installs yay unless it is already present. It is grouped so it
+// can be chained after other commands. makepkg refuses to run as root, so
+// it is never given the sudo prefix.
+const yaySetup = "{ command -v yay > /dev/null || (%spacman -S --needed --noconfirm git base-devel && " +
+	"git clone https://aur.archlinux.org/yay-bin.git /tmp/yay-bin && cd /tmp/yay-bin && makepkg -si --noconfirm); }"
+
+// pacmanSubcommands maps apt and apt-get subcommands to pacman operations.
+// Arch does not support partial upgrades, so refreshing the package
+// lists also upgrades the system.
+var pacmanSubcommands = map[string]string{
+	"install":      "-S --needed",
+	"reinstall":    "-S",
+	"remove":       "-R",
+	"purge":        "-Rns",
+	"autoremove":   "",
+	"update":       "-Syu",
+	"upgrade":      "-Syu",
+	"dist-upgrade": "-Syu",
+	"full-upgrade": "-Syu",
+	"search":       "-Ss",
+	"show":         "-Si",
+	"list":         "-Q",
+	"depends":      "-Si",
+	"rdepends":     "-Sii",
+	"clean":        "-Sc",
+	"autoclean":    "-Sc",
+	"download":     "-Sw",
+}
+
+// pacmanCacheSubcommands maps apt-cache subcommands to pacman operations.
+var pacmanCacheSubcommands = map[string]string{
+	"search":   "-Ss",
+	"show":     "-Si",
+	"showpkg":  "-Si",
+	"madison":  "-Si",
+	"depends":  "-Si",
+	"rdepends": "-Sii",
+	"pkgnames": "-Slq",
+	"policy":   "-Si",
+}
+
+// pacmanOptions maps apt options to pacman's. An empty value means pacman
+// has no counterpart and the option is dropped with a warning.
+var pacmanOptions = map[string]string{
+	"-y":                           "--noconfirm",
+	"--yes":                        "--noconfirm",
+	"--assume-yes":                 "--noconfirm",
+	"-q":                           "--quiet",
+	"-qq":                          "--quiet",
+	"--quiet":                      "--quiet",
+	"-d":                           "--downloadonly",
+	"--download-only":              "--downloadonly",
+	"-s":                           "--print",
+	"--simulate":                   "--print",
+	"--dry-run":                    "--print",
+	"--upgradable":                 "--upgrades",
+	"--no-install-recommends":      "",
+	"--install-recommends":         "",
+	"--allow-unauthenticated":      "",
+	"-m":                           "",
+	"--fix-missing":                "",
+	"--installed":                  "",
+	"-a":                           "",
+	"--all-versions":               "",
+	"-f":                           "",
+	"--fix-broken":                 "",
+	"--allow-downgrades":           "",
+	"--allow-remove-essential":     "",
+	"--allow-change-held-packages": "",
+	"--no-install-suggests":        "",
+	"--no-upgrade":                 "",
+	"--only-upgrade":               "",
+	"--reinstall":                  "",
+	"--purge":                      "",
+	"-o":                           "",
+	"--option":                     "",
+	"-t":                           "",
+	"--target-release":             "",
+	"-c":                           "",
+	"--config-file":                "",
+}
+
+// pacmanArches maps Debian architecture names to Arch Linux's.
+var pacmanArches = map[string]string{
+	"amd64": "x86_64",
+	"arm64": "aarch64",
+	"armhf": "armv7h",
+	"i386":  "i686",
+}
+
+func (archLinux) ID() string             { return "arch" }
+func (archLinux) Name() string           { return "Arch Linux" }
+func (archLinux) PackageManager() string { return "pacman" }
+
+func (archLinux) Subcommand(tool, sub string) (string, bool) {
+	if tool == "apt-cache" {
+		op, ok := pacmanCacheSubcommands[sub]
+		return op, ok
+	}
+	op, ok := pacmanSubcommands[sub]
+	return op, ok
+}
+
+func (archLinux) Option(flag string) (string, bool) {
+	op, ok := pacmanOptions[flag]
+	return op, ok
+}
+
+// Install installs official packages with pacman and AUR packages with
+// yay, which builds them as the invoking user and asks for sudo itself.
+func (archLinux) Install(sudo string, pkgs []string) string {
+	official, aur := splitAUR(pkgs)
+	var parts []string
+	if len(official) > 0 {
+		parts = append(parts, sudo+"pacman -S --needed --noconfirm "+strings.Join(official, " "))
+	}
+	if len(aur) > 0 {
+		parts = append(parts, fmt.Sprintf(yaySetup, sudo), "yay -S --needed --noconfirm "+strings.Join(aur, " "))
+	}
+	return strings.Join(parts, " && ")
+}
+
+func (archLinux) Remove(sudo string, pkgs []string) string {
+	official, aur := splitAUR(pkgs)
+	return sudo + "pacman -Rns --noconfirm " + strings.Join(append(official, aur...), " ")
+}
+
+func (archLinux) GroupInstall(sudo string, ids []string) string {
+	return sudo + "pacman -S --needed --noconfirm " + strings.Join(ids, " ")
+}
+
+// Pin always fails: pacman only installs the version in the repositories.
+func (archLinux) Pin(name, version string) (string, bool) {
+	return "", false
+}
+
+// ExtraRepos, EnableRepo, AddRepoURL and RepoFile always fail. Arch Linux packages
+// software from third-party apt repositories in the AUR instead.
+func (archLinux) ExtraRepos(sudo string, names []string) (string, bool) {
+	return "", false
+}
+
+func (archLinux) EnableRepo(sudo, id string, remove bool) (string, bool) {
+	return "", false
+}
+
+func (archLinux) AddRepoURL(sudo, url string) (string, bool) {
+	return "", false
+}
+
+func (archLinux) RepoFile(id string, repos []Repo) (string, string, bool) {
+	return "", "", false
+}
+
+func (archLinux) ImportKey(sudo, source string) string {
+	if u := strings.Trim(source, `"'`); strings.HasPrefix(u, "http://") || strings.HasPrefix(u, "https://") {
+		return "curl -fsSL " + source + " | " + sudo + "pacman-key --add -"
+	}
+	return sudo + "pacman-key --add " + source
+}
+
+func (archLinux) ListKeys() string {
+	return "pacman-key --list-keys"
+}
+
+func (archLinux) DeleteKey(sudo, id string) string {
+	return sudo + "pacman-key --delete " + id
+}
+
+func (archLinux) Arch(debian string) (string, bool) {
+	arch, ok := pacmanArches[debian]
+	return arch, ok
+}
+
+// DevPackage always fails: Arch Linux ships headers with the libraries.
+func (archLinux) DevPackage(name string) (string, bool) {
+	return "", false
+}
+
+// splitAUR separates AUR packages, with their prefix removed, from the
+// packages in the official repositories.
+func splitAUR(pkgs []string) (official, aur []string) {
+	for _, pkg := range pkgs {
+		if strings.HasPrefix(pkg, aurPrefix) {
+			aur = append(aur, strings.TrimPrefix(pkg, aurPrefix))
+		} else {
+			official = append(official, pkg)
+		}
+	}
+	return official, aur
+}
@@ -0,0 +1,114 @@
+package converter_test
+
+import (
+	"testing"
+
+	"ubuntu-to-fedora/pkg/converter"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestArchLinuxTarget tests the conversion of scripts for Arch Linux
+func TestArchLinuxTarget(t *testing.T) {
+	yay := "{ command -v yay > /dev/null || (sudo pacman -S --needed --noconfirm git base-devel && " +
+		"git clone https://aur.archlinux.org/yay-bin.git /tmp/yay-bin && cd /tmp/yay-bin && makepkg -si --noconfirm); } && "
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		warning  string
+	}{
+		{
+			name:     "Update and upgrade",
+			input:    "sudo apt update && sudo apt upgrade -y",
+			expected: "sudo pacman -Syu && sudo pacman -Syu --noconfirm",
+		},
+		{
+			name:     "Package names",
+			input:    "sudo apt install -y curl fd-find python3-pip",
+			expected: "sudo pacman -S --needed --noconfirm curl fd python-pip",
+		},
+		{
+			name:     "AUR packages",
+			input:    "sudo apt install -y code git",
+			expected: "sudo pacman -S --needed --noconfirm git && " + yay + "yay -S --needed --noconfirm visual-studio-code-bin",
+		},
+		{
+			name:     "Meta-packages",
+			input:    "sudo apt install -y build-essential curl",
+			expected: "sudo pacman -S --needed --noconfirm base-devel && sudo pacman -S --needed --noconfirm curl",
+		},
+		{
+			name:     "Install without packages",
+			input:    "sudo apt --fix-broken install",
+			expected: "true",
+			warning:  "removed apt install without packages: pacman has no equivalent",
+		},
+		{
+			name:     "Query",
+			input:    "apt-cache search ripgrep",
+			expected: "pacman -Ss ripgrep",
+		},
+		{
+			name:     "Subcommand without equivalent",
+			input:    "sudo apt autoremove -y",
+			expected: "true",
+			warning:  "removed apt autoremove: pacman has no equivalent",
+		},
+		{
+			name:     "PPA in the Arch repositories",
+			input:    "sudo add-apt-repository -y ppa:lazygit-team/release",
+			expected: "true",
+			warning:  "removed ppa:lazygit-team/release: its packages are in the Arch Linux repositories",
+		},
+		{
+			name:     "Vendor repository",
+			input:    "echo \"deb [signed-by=/etc/apt/keyrings/docker.gpg] https://download.docker.com/linux/ubuntu noble stable\" | sudo tee /etc/apt/sources.list.d/docker.list\nsudo apt install -y docker-ce docker-ce-cli",
+			expected: "true\nsudo pacman -S --needed --noconfirm docker",
+			warning:  "removed apt repository https://download.docker.com/linux/ubuntu: its packages are in the Arch Linux repositories",
+		},
+		{
+			name:     "Signing key",
+			input:    "curl -fsSL https://example.com/key.asc | sudo apt-key add -",
+			expected: "curl -fsSL https://example.com/key.asc | sudo pacman-key --add -",
+		},
+		{
+			name:     "Downloaded .deb from the AUR",
+			input:    "wget https://dl.google.com/linux/direct/google-chrome-stable_current_amd64.deb\nsudo apt install -y ./google-chrome-stable_current_amd64.deb\nrm google-chrome-stable_current_amd64.deb",
+			expected: "true\n" + yay + "yay -S --needed --noconfirm google-chrome\ntrue",
+			warning:  "removed the download of google-chrome-stable_current_amd64.deb: Arch Linux installs google-chrome instead",
+		},
+		{
+			name:     "Version pin",
+			input:    "sudo apt install -y git=1:2.43.0-1ubuntu7",
+			expected: "sudo pacman -S --needed --noconfirm git",
+			warning:  "dropped the version pin of git=1:2.43.0-1ubuntu7: pacman cannot install a given version",
+		},
+		{
+			name:     "dnf-only conversion",
+			input:    "sudo apt-mark hold firefox",
+			expected: "{ echo 'cannot convert apt-mark hold firefox for Arch Linux' >&2; false; }",
+			warning:  "cannot convert apt-mark hold firefox for Arch Linux",
+		},
+		{
+			name:     "Distribution ID",
+			input:    `[ "$ID" = ubuntu ] && echo ok`,
+			expected: `[ "$ID" = arch ] && echo ok`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converted, report := convertScriptFor(t, tt.input, converter.ArchLinux)
+			assert.Equal(t, tt.expected, converted, "Commands should be converted for Arch Linux")
+			if tt.warning != "" {
+				var messages []string
+				for _, d := range report.Diagnostics {
+					messages = append(messages, d.Message)
+				}
+				assert.Contains(t, messages, tt.warning, "The conversion should be reported")
+			}
+		})
+	}
+}
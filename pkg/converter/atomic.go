@@ -0,0 +1,265 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FedoraAtomic converts scripts for the Fedora Atomic desktops, such as
+// Silverblue and Kinoite, whose host system is an image that dnf cannot
+// change. System packages are layered onto it with rpm-ostree, GUI
+// applications come from Flathub and development tools are installed in a
+// toolbox container. It shares Fedora's catalog entries; entries under
+// fedora-atomic prefix packages with flatpak/ or toolbox/ to install them
+// there.
+var FedoraAtomic Target = fedoraAtomic{}
+
+type fedoraAtomic struct{}
+
+const (
+	flatpakPrefix = "flatpak/"
+	toolboxPrefix = "toolbox/"
+)
+
+// toolboxSetup creates the default toolbox container unless it exists. It
+// is grouped so it can be chained after other commands. toolbox runs
+// rootless, so it is never given the sudo prefix.
+const toolboxSetup = "{ toolbox run true > /dev/null 2>&1 || toolbox create -y; }"
+
+// coprRepoFile is the URL of a COPR project's repository file for the
+// running Fedora release.
+const coprRepoFile = "https://copr.fedorainfracloud.org/coprs/%s/repo/fedora-$(rpm -E %%fedora)/%s-fedora-$(rpm -E %%fedora).repo"
+
+// rpmOstreeSubcommands maps apt and apt-get subcommands to rpm-ostree's.
+// Refreshing the metadata does not upgrade, and upgrades stage a new
+// deployment.
+var rpmOstreeSubcommands = map[string]string{
+	"install":      "install --idempotent",
+	"reinstall":    "",
+	"remove":       "uninstall",
+	"purge":        "uninstall",
+	"autoremove":   "",
+	"update":       "refresh-md",
+	"upgrade":      "upgrade",
+	"dist-upgrade": "upgrade",
+	"full-upgrade": "upgrade",
+	"search":       "search",
+	"clean":        "cleanup --repomd",
+	"autoclean":    "cleanup --repomd",
+}
+
+// rpmOstreeOptions maps apt options to rpm-ostree's. An empty value means
+// rpm-ostree has no counterpart and the option is dropped, with a warning
+// unless rpm-ostree behaves as if given it.
+var rpmOstreeOptions = map[string]string{
+	"-y":                           "",
+	"--yes":                        "",
+	"--assume-yes":                 "",
+	"-q":                           "",
+	"-qq":                          "",
+	"--quiet":                      "",
+	"-s":                           "--dry-run",
+	"--simulate":                   "--dry-run",
+	"--dry-run":                    "--dry-run",
+	"--no-install-recommends":      "",
+	"--install-recommends":         "",
+	"--allow-unauthenticated":      "",
+	"-d":                           "",
+	"--download-only":              "",
+	"-m":                           "",
+	"--fix-missing":                "",
+	"-f":                           "",
+	"--fix-broken":                 "",
+	"--allow-downgrades":           "",
+	"--allow-remove-essential":     "",
+	"--allow-change-held-packages": "",
+	"--no-install-suggests":        "",
+	"--no-upgrade":                 "",
+	"--only-upgrade":               "",
+	"--reinstall":                  "",
+	"--purge":                      "",
+	"-o":                           "",
+	"--option":                     "",
+	"-t":                           "",
+	"--target-release":             "",
+	"-c":                           "",
+	"--config-file":                "",
+}
+
+// rpmOstreeImplied are the apt options rpm-ostree behaves as if given: it
+// never prompts, and its output is brief.
+var rpmOstreeImplied = map[string]bool{
+	"-y":           true,
+	"--yes":        true,
+	"--assume-yes": true,
+	"-q":           true,
+	"-qq":          true,
+	"--quiet":      true,
+}
+
+func (fedoraAtomic) ID() string             { return "fedora-atomic" }
+func (fedoraAtomic) Name() string           { return "Fedora Atomic" }
+func (fedoraAtomic) PackageManager() string { return "rpm-ostree" }
+func (fedoraAtomic) rpm()                   {}
+func (fedoraAtomic) releaseMacro() string   { return "%fedora" }
+
+// family shares Fedora's catalog entries. The Atomic desktops follow the
+// Fedora releases, so they have no release of their own.
+func (fedoraAtomic) family() (name, release string) {
+	return "fedora", ""
+}
+
+// osRelease returns Fedora's ID, which the Atomic desktops set too. They
+// only differ in VARIANT_ID.
+func (fedoraAtomic) osRelease() (id, note string) {
+	return "fedora", ""
+}
+
+func (fedoraAtomic) batchInstalls() {}
+
+func (fedoraAtomic) installNote() string {
+	return "rpm-ostree layers packages onto a new deployment; reboot before using them"
+}
+
+// Subcommand supports apt-cache search only, as rpm-ostree cannot query
+// packages otherwise.
+func (fedoraAtomic) Subcommand(tool, sub string) (string, bool) {
+	if tool == "apt-cache" {
+		if sub == "search" {
+			return "search", true
+		}
+		return "", false
+	}
+	op, ok := rpmOstreeSubcommands[sub]
+	return op, ok
+}
+
+func (fedoraAtomic) Option(flag string) (string, bool) {
+	op, ok := rpmOstreeOptions[flag]
+	return op, ok
+}
+
+func (fedoraAtomic) Implied(flag string) bool {
+	return rpmOstreeImplied[flag]
+}
+
+// Install layers system packages in a single rpm-ostree transaction,
+// installs flatpak/ packages from Flathub and toolbox/ packages with dnf
+// in the toolbox container.
+func (fedoraAtomic) Install(sudo string, pkgs []string) string {
+	host, flatpaks, tools := splitAtomic(pkgs)
+	var parts []string
+	if len(host) > 0 {
+		parts = append(parts, sudo+"rpm-ostree install --idempotent "+strings.Join(host, " "))
+	}
+	if len(flatpaks) > 0 {
+		parts = append(parts, sudo+flathubRemote, sudo+"flatpak install -y flathub "+strings.Join(flatpaks, " "))
+	}
+	if len(tools) > 0 {
+		parts = append(parts, toolboxSetup, "toolbox run sudo dnf install -y "+strings.Join(tools, " "))
+	}
+	return strings.Join(parts, " && ")
+}
+
+func (fedoraAtomic) Remove(sudo string, pkgs []string) string {
+	host, flatpaks, tools := splitAtomic(pkgs)
+	var parts []string
+	if len(host) > 0 {
+		parts = append(parts, sudo+"rpm-ostree uninstall "+strings.Join(host, " "))
+	}
+	if len(flatpaks) > 0 {
+		parts = append(parts, sudo+"flatpak uninstall -y "+strings.Join(flatpaks, " "))
+	}
+	if len(tools) > 0 {
+		parts = append(parts, "toolbox run sudo dnf remove -y "+strings.Join(tools, " "))
+	}
+	return strings.Join(parts, " && ")
+}
+
+// GroupInstall installs groups in the toolbox container. The groups of
+// the catalog are development tools; desktops come with the image.
+func (fedoraAtomic) GroupInstall(sudo string, ids []string) string {
+	return toolboxSetup + " && toolbox run sudo dnf group install -y " + strings.Join(ids, " ")
+}
+
+func (fedoraAtomic) Pin(name, version string) (string, bool) {
+	return name + "-" + version, true
+}
+
+// ExtraRepos layers the release packages of RPM Fusion.
+func (t fedoraAtomic) ExtraRepos(sudo string, names []string) (string, bool) {
+	var urls []string
+	for _, name := range names {
+		if url, ok := rpmFusionReleases[name]; ok {
+			urls = append(urls, url)
+		}
+	}
+	if len(urls) == 0 {
+		return "", false
+	}
+	return t.Install(sudo, urls), true
+}
+
+// EnableRepo downloads the repository file of a COPR project, as the dnf
+// copr plugin does not run on the host.
+func (fedoraAtomic) EnableRepo(sudo, id string, remove bool) (string, bool) {
+	name := strings.ReplaceAll(id, "/", "-")
+	if remove {
+		return sudo + "rm -f /etc/yum.repos.d/" + name + "-fedora-*.repo", true
+	}
+	return sudo + "curl -fsSL --output-dir /etc/yum.repos.d -O " + fmt.Sprintf(coprRepoFile, id, name), true
+}
+
+func (fedoraAtomic) AddRepoURL(sudo, url string) (string, bool) {
+	return sudo + "curl -fsSL --output-dir /etc/yum.repos.d -O " + url, true
+}
+
+func (fedoraAtomic) RepoFile(id string, repos []Repo) (string, string, bool) {
+	return Fedora.RepoFile(id, repos)
+}
+
+// ImportKey saves the key to /etc/pki/rpm-gpg, as the rpm database of the
+// image is read-only. rpm-ostree checks packages against the gpgkey of
+// their repository file.
+func (fedoraAtomic) ImportKey(sudo, source string) string {
+	if u := strings.Trim(source, `"'`); strings.HasPrefix(u, "http://") || strings.HasPrefix(u, "https://") {
+		return sudo + "curl -fsSL --output-dir /etc/pki/rpm-gpg -O " + source
+	}
+	return sudo + "cp " + source + " /etc/pki/rpm-gpg/"
+}
+
+func (fedoraAtomic) ListKeys() string {
+	return Fedora.ListKeys()
+}
+
+// DeleteKey always fails: keys in the read-only rpm database stay until
+// the image drops them.
+func (fedoraAtomic) DeleteKey(sudo, id string) string {
+	return stubText(fmt.Sprintf("cannot delete the signing key %s on Fedora Atomic", id))
+}
+
+func (fedoraAtomic) Arch(debian string) (string, bool) {
+	return Fedora.Arch(debian)
+}
+
+// DevPackage installs development packages in the toolbox container.
+func (fedoraAtomic) DevPackage(name string) (string, bool) {
+	devel, ok := Fedora.DevPackage(name)
+	return toolboxPrefix + devel, ok
+}
+
+// splitAtomic separates flatpak/ and toolbox/ packages, with their prefix
+// removed, from the packages layered on the host.
+func splitAtomic(pkgs []string) (host, flatpaks, tools []string) {
+	for _, pkg := range pkgs {
+		switch {
+		case strings.HasPrefix(pkg, flatpakPrefix):
+			flatpaks = append(flatpaks, strings.TrimPrefix(pkg, flatpakPrefix))
+		case strings.HasPrefix(pkg, toolboxPrefix):
+			tools = append(tools, strings.TrimPrefix(pkg, toolboxPrefix))
+		default:
+			host = append(host, pkg)
+		}
+	}
+	return host, flatpaks, tools
+}
@@ -0,0 +1,122 @@
+package converter_test
+
+import (
+	"testing"
+
+	"ubuntu-to-fedora/pkg/converter"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFedoraAtomicTarget tests the conversion of scripts for the Fedora
+// Atomic desktops
+func TestFedoraAtomicTarget(t *testing.T) {
+	toolbox := "{ toolbox run true > /dev/null 2>&1 || toolbox create -y; } && "
+	flathub := "sudo flatpak remote-add --if-not-exists flathub https://dl.flathub.org/repo/flathub.flatpakrepo && "
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		warning  string
+	}{
+		{
+			name:     "System packages are layered in one transaction",
+			input:    "sudo apt-get install -yq curl htop",
+			expected: "sudo rpm-ostree install --idempotent curl htop",
+			warning:  "rpm-ostree layers packages onto a new deployment; reboot before using them",
+		},
+		{
+			name:     "Installs are merged into the last",
+			input:    "sudo apt install -y curl code\nsudo apt update && sudo apt install -y htop curl",
+			expected: flathub + "sudo flatpak install -y flathub com.visualstudio.code\nsudo rpm-ostree refresh-md && sudo rpm-ostree install --idempotent curl htop",
+			warning:  "merged 2 rpm-ostree installs into this one",
+		},
+		{
+			name:     "Conditional installs keep their own transaction",
+			input:    "sudo apt install -y curl\ncommand -v htop || sudo apt install -y htop\nsudo apt install -y git",
+			expected: "true\ncommand -v htop || sudo rpm-ostree install --idempotent htop\nsudo rpm-ostree install --idempotent curl git",
+		},
+		{
+			name:     "Installs with options of their own are not merged",
+			input:    "sudo apt install -y curl\nsudo apt install --dry-run git",
+			expected: "sudo rpm-ostree install --idempotent curl\nsudo rpm-ostree install --idempotent --dry-run git",
+		},
+		{
+			name:     "Update and upgrade",
+			input:    "sudo apt update && sudo apt upgrade -y",
+			expected: "sudo rpm-ostree refresh-md && sudo rpm-ostree upgrade",
+		},
+		{
+			name:     "GUI apps from Flathub",
+			input:    "sudo apt install -y code curl",
+			expected: "sudo rpm-ostree install --idempotent curl && " + flathub + "sudo flatpak install -y flathub com.visualstudio.code",
+		},
+		{
+			name:     "Development tools in a toolbox",
+			input:    "sudo apt install -y libssl-dev libfoo-dev",
+			expected: toolbox + "toolbox run sudo dnf install -y openssl-devel foo-devel",
+		},
+		{
+			name:     "Package groups in a toolbox",
+			input:    "sudo apt install -y build-essential",
+			expected: toolbox + "toolbox run sudo dnf group install -y development-tools c-development",
+		},
+		{
+			name:     "Desktops come with the image",
+			input:    "sudo apt install -y ubuntu-desktop",
+			expected: "true",
+			warning:  "removed ubuntu-desktop: no packages are needed on Fedora Atomic",
+		},
+		{
+			name:     "Removal",
+			input:    "sudo apt remove -y code htop",
+			expected: "sudo rpm-ostree uninstall htop && sudo flatpak uninstall -y com.visualstudio.code",
+		},
+		{
+			name:     "Downloaded .deb from Flathub",
+			input:    "wget https://dl.google.com/linux/direct/google-chrome-stable_current_amd64.deb\nsudo apt install -y ./google-chrome-stable_current_amd64.deb",
+			expected: "true\n" + flathub + "sudo flatpak install -y flathub com.google.Chrome",
+		},
+		{
+			name:     "COPR project",
+			input:    "sudo add-apt-repository -y ppa:lazygit-team/release",
+			expected: "sudo curl -fsSL --output-dir /etc/yum.repos.d -O https://copr.fedorainfracloud.org/coprs/atim/lazygit/repo/fedora-$(rpm -E %fedora)/atim-lazygit-fedora-$(rpm -E %fedora).repo",
+		},
+		{
+			name:     "Signing key",
+			input:    "curl -fsSL https://example.com/key.asc | sudo apt-key add -",
+			expected: "sudo curl -fsSL --output-dir /etc/pki/rpm-gpg -O https://example.com/key.asc",
+		},
+		{
+			name:     "Release number",
+			input:    "CODENAME=$(lsb_release -cs)",
+			expected: "CODENAME=$(rpm -E %fedora)",
+		},
+		{
+			name:     "Distribution ID",
+			input:    `[ "$ID" = ubuntu ] && echo ok`,
+			expected: `[ "$ID" = fedora ] && echo ok`,
+		},
+		{
+			name:     "dnf-only conversion",
+			input:    "sudo apt-mark hold firefox",
+			expected: "{ echo 'cannot convert apt-mark hold firefox for Fedora Atomic' >&2; false; }",
+			warning:  "cannot convert apt-mark hold firefox for Fedora Atomic",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converted, report := convertScriptFor(t, tt.input, converter.FedoraAtomic)
+			assert.Equal(t, tt.expected, converted, "Commands should be converted for Fedora Atomic")
+			if tt.warning != "" {
+				var messages []string
+				for _, d := range report.Diagnostics {
+					messages = append(messages, d.Message)
+				}
+				assert.Contains(t, messages, tt.warning, "The conversion should be reported")
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package converter
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"os"
@@ -16,6 +17,38 @@ type AppScript struct {
 	FilePath string
 }
 
+// Diagnostic is a note about a construct that could not be converted
+// faithfully, tied to the line it was found on.
+type Diagnostic struct {
+	Line    uint
+	Message string
+}
+
+// Report describes the conversion of a single script.
+type Report struct {
+	FilePath      string
+	Modified      bool
+	Unmapped      []string // packages with no known name on the target
+	Guessed       []string // packages named by naming conventions, as "debian -> target"
+	Unavailable   []string // Debian packages the target's release lacks
+	UnmappedSnaps []string // snaps with no known Flatpak
+	FirewallRules []string // ufw commands with no firewalld equivalent
+	Diagnostics   []Diagnostic
+
+	// AlreadyConverted is set for scripts converted before by the same
+	// tool and rules, HandEdited for converted scripts changed since, and
+	// ConvertedFor to the ID of the target of an earlier conversion for
+	// another distro. Stale is set for scripts converted by another tool
+	// version or rules, which cannot be converted again from their output.
+	// Unparsed is set for scripts left unchanged because they could not be
+	// parsed, with the parse error as a diagnostic.
+	AlreadyConverted bool
+	HandEdited       bool
+	ConvertedFor     string
+	Stale            bool
+	Unparsed         bool
+}
+
 func GetAvailableApps(dir string) ([]AppScript, error) {
 	seen := make(map[string]bool)
 	var apps []AppScript
@@ -85,72 +118,189 @@ func CloneOmakubRepo(destDir string) error {
 }
 
 func ReplaceUbuntuWithFedora(dir string) error {
+	return ReplaceUbuntuWith(dir, Fedora)
+}
+
+// ReplaceUbuntuWith converts the scripts under dir for target and prints
+// a summary of each conversion.
+func ReplaceUbuntuWith(dir string, target Target) error {
+	reports, err := ConvertDirFor(dir, target)
+	if err != nil {
+		return err
+	}
+
+	for _, report := range reports {
+		printReport(report, "Ubuntu", target.Name())
+	}
+
+	fmt.Println("Replacement completed successfully.")
+	return nil
+}
+
+// ConvertDir converts every shell script under dir in place for Fedora and
+// returns a report for each of them.
+func ConvertDir(dir string) ([]Report, error) {
+	return ConvertDirFor(dir, Fedora)
+}
+
+// ConvertDirFor converts every shell script under dir in place for target
+// and returns a report for each of them.
+func ConvertDirFor(dir string, target Target) ([]Report, error) {
+	return convertDir(dir, target.ID(), func(src []byte) ([]byte, Report, error) {
+		return convertScript(src, target)
+	})
+}
+
+// convertDir converts every shell script under dir in place with convert,
+// recording id as the target in their headers.
+func convertDir(dir, id string, convert func([]byte) ([]byte, Report, error)) ([]Report, error) {
+	var reports []Report
+	rules := RuleSetVersion(id)
 	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return fmt.Errorf("error accessing the path %s: %v", path, err)
 		}
 
 		if info.Mode().IsRegular() && strings.HasSuffix(info.Name(), ".sh") {
-			fmt.Printf("Processing file: %s\n", path)
-			return replaceCommandsInFile(path)
+			report, err := replaceCommandsInFile(path, id, rules, convert)
+			if err != nil {
+				return err
+			}
+			reports = append(reports, report)
 		}
 
 		return nil
 	})
 	if err != nil {
-		return fmt.Errorf("error processing directory %s: %v", dir, err)
+		return nil, fmt.Errorf("error processing directory %s: %v", dir, err)
 	}
 
-	fmt.Println("Replacement completed successfully.")
-	return nil
+	return reports, nil
 }
 
-func replaceCommandsInFile(filePath string) error {
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to read file: %v", err)
+// printReport prints the summary of the conversion of a script from the
+// source distro for the target distro, both given by name.
+func printReport(report Report, source, target string) {
+	switch {
+	case report.ConvertedFor != "":
+		fmt.Printf("Skipped %s: it was converted for %s\n", report.FilePath, targetName(report.ConvertedFor))
+		return
+	case report.HandEdited:
+		fmt.Printf("Skipped %s: it was edited by hand after conversion\n", report.FilePath)
+		return
+	case report.Stale:
+		fmt.Printf("Skipped %s: it was converted by older rules; restore the original script to convert it again\n", report.FilePath)
+		return
+	case report.AlreadyConverted:
+		fmt.Printf("Already converted: %s\n", report.FilePath)
+		return
+	case report.Unparsed:
+		fmt.Printf("Skipped %s: it could not be parsed\n", report.FilePath)
+		for _, d := range report.Diagnostics {
+			fmt.Printf("  line %d: %s\n", d.Line, d.Message)
+		}
+		return
 	}
+	if report.Modified {
+		fmt.Printf("Modified file: %s\n", report.FilePath)
+	} else {
+		fmt.Printf("No %s-specific commands found in %s\n", source, report.FilePath)
+	}
+	if len(report.Unmapped) > 0 {
+		fmt.Printf("  Packages with no known %s name: %s\n", target, strings.Join(report.Unmapped, ", "))
+	}
+	if len(report.Guessed) > 0 {
+		fmt.Printf("  Package names guessed for %s, check them: %s\n", target, strings.Join(report.Guessed, ", "))
+	}
+	if len(report.Unavailable) > 0 {
+		fmt.Printf("  Packages not available for %s: %s\n", target, strings.Join(report.Unavailable, ", "))
+	}
+	if len(report.UnmappedSnaps) > 0 {
+		fmt.Printf("  Snaps with no known Flatpak: %s\n", strings.Join(report.UnmappedSnaps, ", "))
+	}
+	if len(report.FirewallRules) > 0 {
+		fmt.Println("  Firewall rules with no firewalld equivalent:")
+		for _, rule := range report.FirewallRules {
+			fmt.Printf("    %s\n", rule)
+		}
+	}
+	for _, d := range report.Diagnostics {
+		fmt.Printf("  line %d: %s\n", d.Line, d.Message)
+	}
+}
 
-	original := string(content)
-
-	replacements := map[string]string{
-		"sudo apt update":     "sudo dnf update",
-		"sudo apt upgrade":    "sudo dnf upgrade",
-		"sudo apt install":    "sudo dnf install",
-		"add-apt-repository":  "sudo dnf config-manager --add-repo",
-		"sudo apt autoremove": "sudo dnf autoremove",
-		"sudo apt-get":        "sudo dnf",
-		"apt-get":             "dnf",
-		"sudo apt":            "sudo dnf",
-		"apt":                 "dnf",
+// targetName returns the name of the target with the given ID.
+func targetName(id string) string {
+	if t, ok := LookupTarget(id); ok {
+		return t.Name()
+	}
+	if id == ubuntuID {
+		return "Ubuntu"
 	}
+	return id
+}
 
-	modified := original
-	keys := make([]string, 0, len(replacements))
-	for k := range replacements {
-		keys = append(keys, k)
+// replaceCommandsInFile converts a script in place with convert and
+// records the conversion for the target with the given ID by the given
+// rules in a header. Scripts already converted are skipped: the header
+// tells whether they were converted by older rules, edited by hand or
+// converted for another target. A script whose original content was put
+// back under its header is converted again. Scripts that do not parse are
+// left alone too.
+func replaceCommandsInFile(filePath, id, rules string, convert func([]byte) ([]byte, Report, error)) (Report, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to read file: %v", err)
 	}
-	for i := 0; i < len(keys)-1; i++ {
-		for j := i + 1; j < len(keys); j++ {
-			if len(keys[i]) < len(keys[j]) {
-				keys[i], keys[j] = keys[j], keys[i]
-			}
+	report := Report{FilePath: filePath}
+
+	source := content
+	if h, body, converted := parseHeader(content); converted {
+		restored := false
+		switch hash := contentHash(body); {
+		case hash == h.source && hash != h.output:
+			restored = true
+		case hash != h.output:
+			report.HandEdited = true
+		case h.target != id:
+			report.ConvertedFor = h.target
+		case h.tool == Version && h.rules == rules:
+			report.AlreadyConverted = true
+		default:
+			// Only the converted output is left, which the rules for
+			// the original script do not apply to.
+			report.Stale = true
 		}
+		if !restored {
+			return report, nil
+		}
+		source = body
 	}
 
-	for _, ubuntuCmd := range keys {
-		modified = strings.ReplaceAll(modified, ubuntuCmd, replacements[ubuntuCmd])
+	modified, convReport, err := convert(source)
+	if err != nil {
+		// A script that does not parse is left alone rather than stopping
+		// the walk halfway through a directory.
+		report.Unparsed = true
+		report.Diagnostics = append(report.Diagnostics, Diagnostic{Line: errorLine(err), Message: err.Error()})
+		return report, nil
+	}
+	convReport.FilePath = filePath
+	report = convReport
+	if bytes.Equal(modified, source) {
+		return report, nil
 	}
 
-	if modified != original {
-		fmt.Printf("Modifying file: %s\n", filePath)
-		err = os.WriteFile(filePath, []byte(modified), 0644)
-		if err != nil {
-			return fmt.Errorf("failed to write modified file: %v", err)
-		}
-	} else {
-		fmt.Printf("No Ubuntu-specific commands found in %s\n", filePath)
+	h := header{tool: Version, rules: rules, source: contentHash(source), target: id}
+	_, stripped, _ := parseHeader(withHeader(modified, h))
+	h.output = contentHash(stripped)
+	modified = withHeader(modified, h)
+
+	report.Modified = true
+	err = os.WriteFile(filePath, modified, 0644)
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to write modified file: %v", err)
 	}
 
-	return nil
+	return report, nil
 }
@@ -3,6 +3,7 @@ package converter_test
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"ubuntu-to-fedora/pkg/converter"
@@ -118,8 +119,17 @@ sudo apt autoremove`,
 sudo dnf update
 sudo dnf install nginx
 sudo dnf upgrade
-sudo dnf config-manager --add-repo ppa:some/repo
+{ echo 'no COPR equivalent known for ppa:some/repo; enable a repository for it on Fedora manually' >&2; false; }
 sudo dnf autoremove`,
+		},
+		{
+			name: "Repair of broken dependencies",
+			input: `#!/bin/bash
+sudo apt-get install -f -y
+sudo apt --fix-broken install`,
+			expected: `#!/bin/bash
+true
+true`,
 		},
 		{
 			name: "Complex commands with options",
@@ -130,7 +140,7 @@ apt list --installed
 sudo apt-get install -y docker-ce docker-ce-cli`,
 			expected: `#!/bin/bash
 sudo dnf update -y
-sudo dnf install -y --no-install-recommends nginx
+sudo dnf install -y --setopt=install_weak_deps=False nginx
 dnf list --installed
 sudo dnf install -y docker-ce docker-ce-cli`,
 		},
@@ -154,6 +164,174 @@ sudo dnf install package1 package2
 ls -la
 wget https://example.com/file
 sudo dnf autoremove`,
+		},
+		{
+			name: "Words, comments and strings containing apt",
+			input: `#!/bin/bash
+# apt is only rewritten where it runs as a command
+echo "Run apt install on your laptop"
+adapter_url="https://example.com/apt/capture.tar.gz"
+wget -O /tmp/capture.tar.gz "$adapter_url"
+sudo apt install -y laptop-mode-tools`,
+			expected: `#!/bin/bash
+# apt is only rewritten where it runs as a command
+echo "Run apt install on your laptop"
+adapter_url="https://example.com/apt/capture.tar.gz"
+wget -O /tmp/capture.tar.gz "$adapter_url"
+sudo dnf install -y laptop-mode-tools`,
+		},
+		{
+			name: "Commands inside substitutions and pipelines",
+			input: `#!/bin/bash
+if ! command -v apt >/dev/null; then exit 1; fi
+yes | sudo -E apt-get install curl
+VERSIONS=$(apt list --installed 2>/dev/null)`,
+			expected: `#!/bin/bash
+if ! command -v apt >/dev/null; then exit 1; fi
+yes | sudo -E dnf install curl
+VERSIONS=$(dnf list --installed 2>/dev/null)`,
+		},
+		{
+			name: "Apt-only options",
+			input: `#!/bin/bash
+sudo apt-get -qq update
+sudo apt-get install -yqq --fix-broken -o Dpkg::Options::="--force-confold" --allow-downgrades curl
+DEBIAN_FRONTEND=noninteractive sudo apt-get -y --allow-unauthenticated install git`,
+			expected: `#!/bin/bash
+sudo dnf -q update
+sudo dnf install -y -q curl
+DEBIAN_FRONTEND=noninteractive sudo dnf -y --nogpgcheck install git`,
+		},
+		{
+			name: "Package names",
+			input: `#!/bin/bash
+sudo apt install -y build-essential libssl-dev python3-venv python3-dev libfoo-dev
+sudo apt-get remove -y gnupg apt-transport-https
+sudo apt install -y apt-transport-https`,
+			expected: `#!/bin/bash
+sudo dnf group install -y development-tools c-development && sudo dnf install -y openssl-devel python3 python3-devel foo-devel
+sudo dnf remove -y gnupg2
+true`,
+		},
+		{
+			name: "PPAs",
+			input: `#!/bin/bash
+sudo add-apt-repository -y ppa:lazygit-team/release
+add-apt-repository --remove "ppa:lazygit-team/release"
+sudo add-apt-repository -y ppa:aslatter/ppa && sudo apt update
+sudo add-apt-repository universe
+sudo add-apt-repository -y ppa:unknown/tool || exit 1`,
+			expected: `#!/bin/bash
+sudo dnf copr enable -y atim/lazygit
+sudo dnf copr remove -y atim/lazygit
+true && sudo dnf update
+true
+{ echo 'no COPR equivalent known for ppa:unknown/tool; enable a repository for it on Fedora manually' >&2; false; } || exit 1`,
+		},
+		{
+			name: "Apt sources files",
+			input: `#!/bin/bash
+echo "deb [arch=$(dpkg --print-architecture) signed-by=/etc/apt/keyrings/docker.gpg] https://download.docker.com/linux/ubuntu $(lsb_release -cs) stable" | sudo tee /etc/apt/sources.list.d/docker.list > /dev/null
+sudo tee /etc/apt/sources.list.d/vendor.sources <<EOF >/dev/null && echo done
+Types: deb
+URIs: https://example.com/apt
+Suites: stable
+Signed-By: /usr/share/keyrings/vendor.gpg
+EOF
+cat <<'EOF' | sudo tee /etc/apt/sources.list.d/mozilla.list
+deb [signed-by=/etc/apt/keyrings/packages.mozilla.org.asc] https://packages.mozilla.org/apt mozilla main
+EOF
+echo "deb https://packages.microsoft.com/repos/code stable main" > /etc/apt/sources.list.d/vscode.list`,
+			expected: `#!/bin/bash
+echo '[docker]
+name=Docker CE
+baseurl=https://download.docker.com/linux/fedora/$releasever/$basearch/stable
+enabled=1
+gpgcheck=1
+gpgkey=https://download.docker.com/linux/fedora/gpg' | sudo tee /etc/yum.repos.d/docker.repo > /dev/null
+{ echo 'no Fedora repository known for https://example.com/apt; install its packages manually' >&2; false; } && echo done
+true
+echo '[vscode]
+name=Visual Studio Code
+baseurl=https://packages.microsoft.com/yumrepos/vscode
+enabled=1
+gpgcheck=1
+gpgkey=https://packages.microsoft.com/keys/microsoft.asc' | tee /etc/yum.repos.d/vscode.repo > /dev/null`,
+		},
+		{
+			name: "Signing keys",
+			input: `#!/bin/bash
+wget -qO- https://example.com/key.asc | sudo apt-key add -
+sudo apt-key adv --keyserver hkp://keyserver.ubuntu.com:80 --recv-keys 0xABCDEF0123456789
+curl -fsSL https://example.com/tool.asc | sudo gpg --dearmor -o /usr/share/keyrings/tool.gpg
+echo "deb [signed-by=/usr/share/keyrings/tool.gpg] https://example.com/tool stable main" | sudo tee /etc/apt/sources.list.d/tool.list
+curl -fsSL https://example.com/other.asc | gpg --dearmor | sudo tee /etc/apt/keyrings/other.gpg > /dev/null
+sudo curl -fsSLo /usr/share/keyrings/third.asc https://example.com/third.asc`,
+			expected: `#!/bin/bash
+sudo rpm --import https://example.com/key.asc
+sudo rpm --import 'https://keyserver.ubuntu.com/pks/lookup?op=get&search=0xABCDEF0123456789'
+sudo rpm --import https://example.com/tool.asc
+{ echo 'no Fedora repository known for https://example.com/tool; install its packages manually' >&2; false; }
+sudo rpm --import https://example.com/other.asc
+sudo rpm --import https://example.com/third.asc`,
+		},
+		{
+			name: "Copied keyrings",
+			input: `#!/bin/bash
+set -e
+curl -fsSL https://download.docker.com/linux/ubuntu/gpg | gpg --dearmor > docker.gpg
+sudo mv docker.gpg /etc/apt/keyrings/
+echo "deb [signed-by=/etc/apt/keyrings/docker.gpg] https://download.docker.com/linux/ubuntu jammy stable" | sudo tee /etc/apt/sources.list.d/docker.list
+curl -fsSL https://example.com/other.asc | gpg --dearmor > other.gpg
+sudo cp other.gpg /usr/share/keyrings/other.gpg
+rm other.gpg`,
+			expected: `#!/bin/bash
+set -e
+true
+true
+echo '[docker]
+name=Docker CE
+baseurl=https://download.docker.com/linux/fedora/$releasever/$basearch/stable
+enabled=1
+gpgcheck=1
+gpgkey=https://download.docker.com/linux/fedora/gpg' | sudo tee /etc/yum.repos.d/docker.repo > /dev/null
+sudo rpm --import https://example.com/other.asc
+true
+true`,
+		},
+		{
+			name: "Downloaded .deb packages",
+			input: `#!/bin/bash
+cd /tmp
+wget https://dl.google.com/linux/direct/google-chrome-stable_current_amd64.deb
+sudo apt install -y ./google-chrome-stable_current_amd64.deb
+rm google-chrome-stable_current_amd64.deb
+wget -O code.deb 'https://update.code.visualstudio.com/latest/linux-deb-x64/stable'
+sudo dpkg -i code.deb
+rm -f /tmp/code.deb
+curl -Lo /tmp/tool.deb https://example.com/tool_amd64.deb
+sudo apt install -y /tmp/tool.deb
+rm /tmp/tool.deb`,
+			expected: `#!/bin/bash
+cd /tmp
+wget https://dl.google.com/linux/direct/google-chrome-stable_current_x86_64.rpm
+sudo dnf install -y ./google-chrome-stable_current_x86_64.rpm
+rm google-chrome-stable_current_x86_64.rpm
+wget -O code.rpm 'https://update.code.visualstudio.com/latest/linux-rpm-x64/stable'
+sudo dnf install -y code.rpm
+rm -f /tmp/code.rpm
+curl -Lo /tmp/tool.deb https://example.com/tool_amd64.deb
+{ echo 'cannot convert the tool.deb download from line 9: no RPM is known for https://example.com/tool_amd64.deb' >&2; false; }
+rm /tmp/tool.deb`,
+		},
+		{
+			name: "Downloaded .deb package with other packages",
+			input: `#!/bin/bash
+wget https://dl.google.com/linux/direct/google-chrome-stable_current_amd64.deb
+sudo apt install -y ./google-chrome-stable_current_amd64.deb git`,
+			expected: `#!/bin/bash
+wget https://dl.google.com/linux/direct/google-chrome-stable_current_x86_64.rpm
+sudo dnf install -y ./google-chrome-stable_current_x86_64.rpm git`,
 		},
 		{
 			name: "No Ubuntu commands",
@@ -194,7 +372,7 @@ wget https://example.com/file`,
 				t.Fatalf("Failed to read modified script: %v", err)
 			}
 
-			assert.Equal(t, tt.expected, string(modifiedScript), "The script should have correct Fedora replacements")
+			assert.Equal(t, tt.expected, withoutHeader(string(modifiedScript)), "The script should have correct Fedora replacements")
 		})
 	}
 
@@ -223,6 +401,125 @@ wget https://example.com/file`,
 	})
 }
 
+// convertScript writes script to a temporary directory, converts it and
+// returns the converted script with its report
+func convertScript(t *testing.T, script string) (string, converter.Report) {
+	t.Helper()
+	return convertScriptFor(t, script, converter.Fedora)
+}
+
+// convertScriptFor is convertScript for the given target
+func convertScriptFor(t *testing.T, script string, target converter.Target) (string, converter.Report) {
+	t.Helper()
+	tempDir := t.TempDir()
+	scriptPath := filepath.Join(tempDir, "script.sh")
+	if err := os.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+		t.Fatalf("Failed to write test script: %v", err)
+	}
+
+	reports, err := converter.ConvertDirFor(tempDir, target)
+	if err != nil {
+		t.Fatalf("Failed to convert test script: %v", err)
+	}
+
+	converted, err := os.ReadFile(scriptPath)
+	if err != nil {
+		t.Fatalf("Failed to read converted script: %v", err)
+	}
+	return withoutHeader(string(converted)), reports[0]
+}
+
+// withoutHeader removes the conversion header from a converted script, so
+// tests can compare the converted commands alone.
+func withoutHeader(script string) string {
+	lines := strings.SplitAfter(script, "\n")
+	for i, line := range lines {
+		if i < 2 && strings.HasPrefix(line, "# Converted by ubuntu-to-fedora:") {
+			return strings.Join(append(lines[:i:i], lines[i+1:]...), "")
+		}
+	}
+	return script
+}
+
+// TestConvertDirReports tests the per-script conversion reports
+func TestConvertDirReports(t *testing.T) {
+	tempDir := t.TempDir()
+
+	scripts := map[string]string{
+		"tools.sh": "sudo apt install -y curl nginx docker-ce nginx\n",
+		"plain.sh": "echo 'nothing to do'\n",
+		"flags.sh": "sudo apt-get install -y --fix-broken curl\n",
+		"devel.sh": "sudo apt install -y libssl-dev libfoo-dev libsqlite3-dev\n",
+	}
+	for name, content := range scripts {
+		err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatalf("Failed to write test script %s: %v", name, err)
+		}
+	}
+
+	reports, err := converter.ConvertDir(tempDir)
+	assert.NoError(t, err, "Expected no error during conversion")
+	assert.Len(t, reports, 4, "Expected one report per script")
+
+	byName := make(map[string]converter.Report)
+	for _, report := range reports {
+		byName[filepath.Base(report.FilePath)] = report
+	}
+
+	assert.True(t, byName["tools.sh"].Modified, "Expected tools.sh to be modified")
+	assert.Equal(t, []string{"nginx", "docker-ce"}, byName["tools.sh"].Unmapped, "Expected unmapped packages to be listed once each")
+	assert.False(t, byName["plain.sh"].Modified, "Expected plain.sh to be left alone")
+	assert.Empty(t, byName["plain.sh"].Unmapped, "Expected no unmapped packages in plain.sh")
+	assert.Equal(t, []string{"libfoo-dev -> foo-devel"}, byName["devel.sh"].Guessed, "Expected only the guessed name to be reported")
+	assert.Empty(t, byName["tools.sh"].Guessed, "Expected table hits not to be reported as guesses")
+
+	diagnostics := byName["flags.sh"].Diagnostics
+	if assert.Len(t, diagnostics, 1, "Expected the dropped option to be reported") {
+		assert.Equal(t, uint(1), diagnostics[0].Line, "Expected the diagnostic to point at line 1")
+		assert.Contains(t, diagnostics[0].Message, "--fix-broken", "Expected the diagnostic to name the option")
+	}
+}
+
+// TestConvertDirUnparsable tests that a script that does not parse is
+// reported and does not stop the rest of the directory from converting
+func TestConvertDirUnparsable(t *testing.T) {
+	tempDir := t.TempDir()
+
+	scripts := map[string]string{
+		"a-good.sh": "sudo apt install -y curl\n",
+		"b-bad.sh":  "sudo apt install -y curl\nif true; then\n",
+		"c-good.sh": "sudo apt-get install -y git\n",
+	}
+	for name, content := range scripts {
+		err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatalf("Failed to write test script %s: %v", name, err)
+		}
+	}
+
+	reports, err := converter.ConvertDir(tempDir)
+	assert.NoError(t, err, "Expected a bad script not to fail the conversion")
+	assert.Len(t, reports, 3, "Expected one report per script")
+
+	for _, report := range reports {
+		name := filepath.Base(report.FilePath)
+		content, err := os.ReadFile(report.FilePath)
+		assert.NoError(t, err)
+		if name == "b-bad.sh" {
+			assert.True(t, report.Unparsed, "Expected the bad script to be reported")
+			assert.False(t, report.Modified)
+			assert.Equal(t, scripts[name], string(content), "Expected the bad script to be left alone")
+			if assert.Len(t, report.Diagnostics, 1) {
+				assert.NotZero(t, report.Diagnostics[0].Line, "Expected the parse error to point at a line")
+			}
+			continue
+		}
+		assert.True(t, report.Modified, "Expected %s to be converted", name)
+		assert.Contains(t, string(content), "dnf install", "Expected %s to be converted", name)
+	}
+}
+
 // TestCloneOmakubRepo tests the repository cloning functionality
 func TestCloneOmakubRepo(t *testing.T) {
 	// Create a temporary directory
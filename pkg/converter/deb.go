@@ -0,0 +1,244 @@
+package converter
+
+import (
+	_ "embed"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+//go:embed data/debs.json
+var debsJSON []byte
+
+// debCatalog lists vendor .deb download URLs that have a counterpart on
+// the target distros. Each pattern is matched against the URL as written
+// in the script and replaced with the distro's value, which may refer to
+// capture groups. "<distro>_package" instead names a package that
+// replaces the download, such as an AUR package for Arch Linux, and
+// "nixpkgs" the attribute of generated Nix modules.
+type debCatalog struct {
+	Debs []map[string]string `json:"debs"`
+}
+
+type debRule struct {
+	pattern *regexp.Regexp
+	targets map[string]string
+}
+
+var (
+	debs     debCatalog
+	debRules []debRule
+)
+
+func init() {
+	mustUnmarshal("debs.json", debsJSON, &debs)
+	for _, d := range debs.Debs {
+		debRules = append(debRules, debRule{regexp.MustCompile(d["pattern"]), d})
+	}
+}
+
+// debDownload is a .deb file fetched by the script. The download, the
+// install of the file and its removal are converted as one unit.
+type debDownload struct {
+	url     string
+	line    uint
+	debBase string
+	rpmBase string // empty when no RPM is known for the download
+	pkg     string // the package installed instead of the download
+}
+
+// lookupDeb returns the catalog entry of a known .deb download URL.
+func lookupDeb(text string) (map[string]string, bool) {
+	for _, rule := range debRules {
+		if rule.pattern.MatchString(text) {
+			return rule.targets, true
+		}
+	}
+	return nil, false
+}
+
+// debTarget returns text with a known .deb download URL replaced by the
+// vendor's URL for the target, or the package replacing the download.
+func (r *rewriter) debTarget(text string) (newURL, pkg string, ok bool) {
+	for _, rule := range debRules {
+		if !rule.pattern.MatchString(text) {
+			continue
+		}
+		if pkg, _ := catalogValue(r.target, rule.targets, "_package"); pkg != "" {
+			return "", pkg, true
+		}
+		if replacement, found := catalogValue(r.target, rule.targets, ""); found {
+			return rule.pattern.ReplaceAllString(text, replacement), "", true
+		}
+	}
+	return "", "", false
+}
+
+// packageKind names the target's package format in messages, and
+// packageTool the low-level tool installing it.
+func (r *rewriter) packageKind() string {
+	if r.rpm() {
+		return "RPM"
+	}
+	return "package"
+}
+
+func (r *rewriter) packageTool() string {
+	if r.rpm() {
+		return "rpm"
+	}
+	return r.target.PackageManager()
+}
+
+// urlBase returns the file name a download of rawURL is saved under when
+// no output file is given.
+func urlBase(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		return path.Base(u.Path)
+	}
+	return path.Base(rawURL)
+}
+
+// replaceBase swaps the file name at the end of text, keeping its
+// directory and any closing quote.
+func replaceBase(text, oldBase, newBase string) string {
+	i := strings.LastIndex(text, oldBase)
+	if i < 0 {
+		return text
+	}
+	return text[:i] + newBase + text[i+len(oldBase):]
+}
+
+// trackDebDownload records a curl or wget command that fetches a .deb and
+// points it at the vendor's RPM when one is known, or drops it when the
+// target installs a package in its place.
+func (r *rewriter) trackDebDownload(cmd command) {
+	urlWord, output, ok := r.downloadURL(cmd)
+	if !ok {
+		return
+	}
+	rawURL, _ := r.template(urlWord)
+	outputWord := r.outputWord(cmd)
+
+	base := urlBase(rawURL)
+	if output != "" {
+		base = path.Base(output)
+	}
+	newURL, pkg, known := r.debTarget(r.text(urlWord))
+	if !strings.HasSuffix(base, ".deb") && !known {
+		return
+	}
+
+	d := &debDownload{url: rawURL, line: cmd.call.Pos().Line(), debBase: base, pkg: pkg}
+	switch {
+	case pkg != "":
+		r.replace(cmd.call, "true")
+		r.warn(cmd.call, "removed the download of %s: %s installs %s instead", base, r.target.Name(), packageName(pkg))
+	case known:
+		r.replace(urlWord, newURL)
+		if outputWord != nil {
+			d.rpmBase = strings.TrimSuffix(base, ".deb") + ".rpm"
+			r.replace(outputWord, replaceBase(r.text(outputWord), base, d.rpmBase))
+		} else {
+			d.rpmBase = urlBase(strings.Trim(newURL, `"'`))
+		}
+	}
+
+	if r.debs == nil {
+		r.debs = make(map[string]*debDownload)
+	}
+	r.debs[base] = d
+}
+
+// outputWord returns the argument naming the output file of curl or wget.
+func (r *rewriter) outputWord(cmd command) *syntax.Word {
+	for i, arg := range cmd.args {
+		if isOutputFlag(cmd.name.Lit(), arg.Lit()) && i+1 < len(cmd.args) {
+			return cmd.args[i+1]
+		}
+	}
+	return nil
+}
+
+// debReplacement returns the text replacing a .deb file argument of an
+// install command, or the package installed in its place. When the
+// package cannot be installed on the target, cmd is replaced by a failing
+// stub and ok is false.
+func (r *rewriter) debReplacement(cmd command, file *syntax.Word) (string, bool) {
+	name, _ := r.template(file)
+	base := path.Base(name)
+	d, ok := r.debs[base]
+	switch {
+	case !ok:
+		r.stub(cmd, fmt.Sprintf("cannot install %s on %s: no %s is known for it", base, r.target.Name(), r.packageKind()))
+		return "", false
+	case d.pkg != "":
+		return d.pkg, true
+	case d.rpmBase == "":
+		r.stub(cmd, fmt.Sprintf("cannot convert the %s download from line %d: no %s is known for %s", base, d.line, r.packageKind(), d.url))
+		return "", false
+	}
+	return replaceBase(r.text(file), base, d.rpmBase), true
+}
+
+// rewriteDebTool converts dpkg -i and gdebi, which install local .deb
+// files, and reports whether cmd was such an install.
+func (r *rewriter) rewriteDebTool(cmd command) bool {
+	var debFile *syntax.Word
+	install := cmd.name.Lit() == "gdebi"
+	for _, arg := range cmd.args {
+		switch lit := arg.Lit(); {
+		case lit == "-i" || lit == "--install":
+			install = true
+		case strings.HasPrefix(lit, "-"):
+		default:
+			if file, _ := r.template(arg); strings.HasSuffix(file, ".deb") {
+				debFile = arg
+			}
+		}
+	}
+	if !install || debFile == nil {
+		return false
+	}
+
+	if pkg, ok := r.debReplacement(cmd, debFile); ok {
+		r.replace(cmd.call, r.target.Install(r.sudoText(cmd), []string{pkg}))
+	}
+	return true
+}
+
+// rewriteDebCleanup renames .deb files removed by rm once their download
+// has been converted to an RPM, and drops those replaced by a package.
+// Keyring files are dropped too, since their downloads no longer write
+// them.
+func (r *rewriter) rewriteDebCleanup(cmd command) {
+	var files, dropped []*syntax.Word
+	for _, arg := range cmd.args {
+		if strings.HasPrefix(arg.Lit(), "-") {
+			continue
+		}
+		files = append(files, arg)
+		file, _ := r.template(arg)
+		d, ok := r.debs[path.Base(file)]
+		switch {
+		case r.keyrings[file] != nil:
+			dropped = append(dropped, arg)
+		case !ok:
+		case d.pkg != "":
+			dropped = append(dropped, arg)
+		case d.rpmBase != "":
+			r.replace(arg, replaceBase(r.text(arg), d.debBase, d.rpmBase))
+		}
+	}
+	if len(dropped) > 0 && len(dropped) == len(files) {
+		r.replace(cmd.call, "true")
+		return
+	}
+	for _, arg := range dropped {
+		r.removeArg(cmd.call, arg)
+	}
+}
@@ -0,0 +1,65 @@
+package converter
+
+// FedoraDNF5 converts scripts for Fedora 41 and later, whose dnf is dnf5.
+// It shares Fedora's catalog entries and differs from Fedora only in the
+// spelling of the commands dnf5 changed.
+var FedoraDNF5 Target = fedoraDNF5{}
+
+type fedoraDNF5 struct {
+	fedora
+}
+
+// dnf5 is the grammar of dnf5. versionlock is built in, and automatic.conf
+// only exists once copied from the defaults shipped with the plugin.
+var dnf5 = &dnfGrammar{
+	markUser:         "dnf mark user",
+	markDependency:   "dnf mark dependency",
+	userInstalled:    `dnf repoquery --userinstalled --queryformat '%{name}\n'`,
+	automaticPackage: "dnf5-plugin-automatic",
+	automaticConfig:  "cp --update=none /usr/share/dnf5/dnf5-plugins/automatic.conf " + dnfAutomaticConf,
+	automaticTimer:   "dnf5-automatic.timer",
+}
+
+// dnf5CacheSubcommands overrides the apt-cache mappings whose query
+// formats need the newline dnf5 no longer adds.
+var dnf5CacheSubcommands = map[string]string{
+	"madison":  `repoquery --showduplicates --queryformat '%{name} | %{evr} | %{repoid}\n'`,
+	"pkgnames": `repoquery --queryformat '%{name}\n'`,
+}
+
+// dnf5Options overrides the apt option mappings dnf5 spells differently.
+var dnf5Options = map[string]string{
+	"--allow-unauthenticated": "--no-gpgchecks",
+}
+
+func (fedoraDNF5) ID() string           { return "fedora-dnf5" }
+func (fedoraDNF5) Name() string         { return "Fedora 41+" }
+func (fedoraDNF5) grammar() *dnfGrammar { return dnf5 }
+
+// osRelease returns Fedora's ID, which dnf5 did not change.
+func (fedoraDNF5) osRelease() (id, note string) {
+	return "fedora", ""
+}
+
+func (fedoraDNF5) family() (name, release string) {
+	return "fedora", "41"
+}
+
+func (t fedoraDNF5) Subcommand(tool, sub string) (string, bool) {
+	if op, ok := dnf5CacheSubcommands[sub]; ok && tool == "apt-cache" {
+		return op, true
+	}
+	return t.fedora.Subcommand(tool, sub)
+}
+
+func (t fedoraDNF5) Option(flag string) (string, bool) {
+	if op, ok := dnf5Options[flag]; ok {
+		return op, true
+	}
+	return t.fedora.Option(flag)
+}
+
+// AddRepoURL uses config-manager's addrepo, which replaced --add-repo.
+func (fedoraDNF5) AddRepoURL(sudo, url string) (string, bool) {
+	return sudo + "dnf config-manager addrepo --from-repofile=" + url, true
+}
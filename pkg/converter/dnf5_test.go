@@ -0,0 +1,46 @@
+package converter_test
+
+import (
+	"testing"
+
+	"ubuntu-to-fedora/pkg/converter"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDNF5Grammar tests conversions that dnf5 spells differently
+func TestDNF5Grammar(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Repository URL",
+			input:    "sudo add-apt-repository -y https://example.com/tool.repo",
+			expected: "sudo dnf config-manager addrepo --from-repofile=https://example.com/tool.repo",
+		},
+		{
+			name:     "Built-in versionlock",
+			input:    "sudo apt-mark hold firefox",
+			expected: "sudo dnf versionlock add firefox",
+		},
+		{
+			name:     "Package names",
+			input:    "sudo apt install -y curl python3-pip",
+			expected: "sudo dnf install -y curl python3-pip",
+		},
+		{
+			name:     "Catalog override",
+			input:    "sudo apt install -y software-properties-common",
+			expected: "sudo dnf install -y dnf5-plugins",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converted, _ := convertScriptFor(t, tt.input, converter.FedoraDNF5)
+			assert.Equal(t, tt.expected, converted, "Commands should use dnf5 syntax")
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// dpkgFields maps dpkg-query --showformat fields to rpm --queryformat tags.
+var dpkgFields = map[string]string{
+	"Package":        "%{NAME}",
+	"binary:Package": "%{NAME}",
+	"Version":        "%{VERSION}-%{RELEASE}",
+	"Architecture":   "%{ARCH}",
+	"Installed-Size": "%{SIZE}",
+	"Description":    "%{SUMMARY}",
+	"binary:Summary": "%{SUMMARY}",
+	"Maintainer":     "%{PACKAGER}",
+	"Homepage":       "%{URL}",
+}
+
+var (
+	dpkgFieldPattern = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+	// dpkgListPattern matches a grep for an installed package in dpkg -l
+	// output, such as '^ii  curl' or "^ii\s+curl ", capturing the name and
+	// the rest of the pattern.
+	dpkgListPattern = regexp.MustCompile(`^\^?ii(?:\s|\\s|\[\[:space:\]\]|[+*])*([a-z0-9][a-z0-9.+-]*)(.*)`)
+
+	packageNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9.+-]*$`)
+)
+
+// dpkgQuery is a dpkg or dpkg-query invocation split into its action,
+// its output format and its package or file operands.
+type dpkgQuery struct {
+	command
+	action   string
+	format   *syntax.Word
+	operands []*syntax.Word
+}
+
+func (r *rewriter) parseDpkgQuery(cmd command) dpkgQuery {
+	q := dpkgQuery{command: cmd}
+	for i := 0; i < len(cmd.args); i++ {
+		arg := cmd.args[i]
+		lit, _ := r.template(arg)
+		switch {
+		case (lit == "-f" || lit == "--showformat") && i+1 < len(cmd.args):
+			i++
+			q.format = cmd.args[i]
+		case strings.HasPrefix(lit, "-f") || strings.HasPrefix(lit, "--showformat="):
+			q.format = arg
+		case strings.HasPrefix(lit, "-") && q.action == "":
+			q.action = lit
+		case strings.HasPrefix(lit, "-"):
+		default:
+			q.operands = append(q.operands, arg)
+		}
+	}
+	return q
+}
+
+// rewriteDpkg converts dpkg and dpkg-query commands. Package removals
+// work for any target; queries become rpm queries on dnf targets.
+func (r *rewriter) rewriteDpkg(cmd command) {
+	if cmd.name.Lit() == "dpkg" && r.rewriteDebTool(cmd) {
+		return
+	}
+
+	q := r.parseDpkgQuery(cmd)
+	switch q.action {
+	case "-r", "--remove", "-P", "--purge":
+		r.replace(cmd.call, r.target.Remove(r.sudoText(cmd), []string{r.queryPackages(q.operands)}))
+		return
+	case "--configure":
+		r.replace(cmd.call, "true")
+		r.warn(cmd.call, "removed dpkg --configure: %s configures packages when they are installed", r.packageTool())
+		return
+	case "--print-architecture":
+		r.printArches = append(r.printArches, cmd)
+		return
+	}
+	if !r.requireRPM(cmd) {
+		return
+	}
+
+	switch q.action {
+	case "-l", "--list":
+		if len(q.operands) == 0 {
+			r.replaceCommand(cmd, "rpm -qa", false)
+		} else {
+			r.replaceCommand(cmd, "rpm -q "+r.queryPackages(q.operands), false)
+		}
+	case "-s", "--status":
+		r.replaceCommand(cmd, "rpm -qi "+r.queryPackages(q.operands), false)
+	case "-W", "--show":
+		query := "rpm -q"
+		if len(q.operands) == 0 {
+			query = "rpm -qa"
+		}
+		if q.format != nil {
+			format, missing := r.rpmFormat(q.format)
+			if len(missing) > 0 {
+				// rpm would print the fields literally, so checks of
+				// their value would silently fail.
+				r.stub(cmd, fmt.Sprintf("rpm has no tag for the dpkg-query field %s; check installed packages with rpm -q --quiet", strings.Join(missing, ", ")))
+				return
+			}
+			query += " --queryformat " + format
+		}
+		if len(q.operands) > 0 {
+			query += " " + r.queryPackages(q.operands)
+		}
+		r.replaceCommand(cmd, query, false)
+	case "-L", "--listfiles":
+		r.replaceCommand(cmd, "rpm -ql "+r.queryPackages(q.operands), false)
+	case "-S", "--search":
+		r.replaceCommand(cmd, "rpm -qf "+r.wordsText(q.operands), false)
+	case "--get-selections":
+		r.replaceCommand(cmd, `rpm -qa --queryformat '%{NAME}\tinstall\n'`, false)
+	default:
+		r.stub(cmd, fmt.Sprintf("cannot convert %s %s", cmd.name.Lit(), r.wordsText(cmd.args)))
+	}
+}
+
+// queryPackages returns the target's names of package operands, keeping
+// names that have no known mapping.
+func (r *rewriter) queryPackages(words []*syntax.Word) string {
+	var names []string
+	for _, w := range words {
+		name := w.Lit()
+		if !packageNamePattern.MatchString(name) {
+			names = append(names, r.text(w))
+			continue
+		}
+		mapped, ok := r.translatePackage(name)
+		if !ok || len(mapped) == 0 {
+			if !ok {
+				r.unmapped(name)
+			}
+			names = append(names, name)
+			continue
+		}
+		for _, m := range mapped {
+			names = append(names, packageName(m))
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+// rpmFormat converts a dpkg-query format to an rpm query format. missing
+// lists the fields, such as ${Status}, that rpm has no tag for.
+func (r *rewriter) rpmFormat(format *syntax.Word) (text string, missing []string) {
+	text = r.text(format)
+	for _, prefix := range []string{"--showformat=", "-f=", "-f"} {
+		if strings.HasPrefix(text, prefix) {
+			text = strings.TrimPrefix(text, prefix)
+			break
+		}
+	}
+	text = dpkgFieldPattern.ReplaceAllStringFunc(text, func(field string) string {
+		name := dpkgFieldPattern.FindStringSubmatch(field)[1]
+		if tag, ok := dpkgFields[name]; ok {
+			return tag
+		}
+		missing = append(missing, field)
+		return field
+	})
+	return text, missing
+}
+
+// rewriteDpkgPipeline handles dpkg queries whose output is parsed by the
+// rest of a pipeline. Installed-package checks such as
+//
+//	dpkg -l | grep -q '^ii  curl '
+//	dpkg -s curl | grep -q 'install ok installed'
+//	dpkg-query -W -f='${Status}' curl | grep -q 'ok installed'
+//
+// become rpm -q --quiet. Greps matching names by prefix or substring,
+// such as '^ii  docker', become a match of rpm -qa against a glob. Other
+// pipelines are flagged, since rpm prints differently formatted output.
+// It reports whether stmt was rewritten.
+func (r *rewriter) rewriteDpkgPipeline(stmt *syntax.Stmt) bool {
+	stages := pipeline(stmt)
+	if len(stages) < 2 {
+		return false
+	}
+	first, ok := stmtCommand(stages[0])
+	if !ok || (first.name.Lit() != "dpkg" && first.name.Lit() != "dpkg-query") || !r.rpm() {
+		return false
+	}
+	q := r.parseDpkgQuery(first)
+
+	if name, glob, ok := r.installedCheck(q, stages[1:]); ok {
+		mapped := name
+		if names, ok := r.translatePackage(name); ok && len(names) > 0 {
+			mapped = strings.Join(names, " ")
+		} else if !ok {
+			r.unmapped(name)
+		}
+		switch {
+		case glob == "":
+			r.replace(&pipelineRange{stmt}, "rpm -q --quiet "+mapped)
+		case mapped != name:
+			r.replace(&pipelineRange{stmt}, "rpm -q --quiet "+mapped)
+			r.warn(stmt, "grep matched installed packages starting with %s; rpm -q --quiet only checks for %s", name, mapped)
+		default:
+			r.replace(&pipelineRange{stmt}, "rpm -qa "+shellQuote(glob)+" | grep -q .")
+		}
+		return true
+	}
+
+	r.flagPipeline(stmt, "output of %s is parsed by a pipeline; rpm output is formatted differently, check it by hand", first.name.Lit())
+	return false
+}
+
+// installedCheck matches a grep over dpkg query output that only tests
+// whether a package is installed, and returns the package name. When the
+// grep also matches other packages, glob is the rpm -qa pattern matching
+// the same names.
+func (r *rewriter) installedCheck(q dpkgQuery, rest []*syntax.Stmt) (name, glob string, ok bool) {
+	if len(rest) != 1 {
+		return "", "", false
+	}
+	grep, isCmd := stmtCommand(rest[0])
+	if !isCmd || grep.name.Lit() != "grep" {
+		return "", "", false
+	}
+
+	quiet := false
+	var pattern string
+	for _, arg := range grep.args {
+		lit, _ := r.template(arg)
+		switch {
+		case strings.HasPrefix(lit, "-") && !strings.HasPrefix(lit, "--"):
+			quiet = quiet || strings.Contains(lit, "q")
+		case lit == "--quiet" || lit == "--silent":
+			quiet = true
+		case strings.HasPrefix(lit, "-"):
+		case pattern == "":
+			pattern = lit
+		}
+	}
+	if !quiet && !devNullRedirect(rest[0]) {
+		return "", "", false
+	}
+
+	var operand string
+	if len(q.operands) == 1 {
+		operand = q.operands[0].Lit()
+	}
+	switch q.action {
+	case "-l", "--list":
+		if m := dpkgListPattern.FindStringSubmatch(pattern); m != nil && operand == "" {
+			if endsPackageName(m[2]) {
+				return m[1], "", true
+			}
+			return m[1], m[1] + "*", true
+		}
+		if operand == "" && packageNamePattern.MatchString(pattern) {
+			return pattern, "*" + pattern + "*", true
+		}
+		if operand != "" && strings.HasPrefix(pattern, "^ii") {
+			return operand, "", true
+		}
+	case "-s", "--status", "-W", "--show":
+		if operand != "" && strings.Contains(pattern, "ok installed") {
+			return operand, "", true
+		}
+	}
+	return "", "", false
+}
+
+// endsPackageName reports whether the rest of a grep pattern after a
+// package name ends the name, so only that package matches.
+func endsPackageName(rest string) bool {
+	for _, end := range []string{" ", "\t", `\t`, `\s`, "[[:space:]]", "$", `\b`, ":"} {
+		if strings.HasPrefix(rest, end) {
+			return true
+		}
+	}
+	return false
+}
+
+// devNullRedirect reports whether stmt sends its standard output to
+// /dev/null.
+func devNullRedirect(stmt *syntax.Stmt) bool {
+	for _, redir := range stmt.Redirs {
+		if (redir.Op == syntax.RdrOut || redir.Op == syntax.RdrAll) && redir.Word.Lit() == "/dev/null" {
+			if redir.N == nil || redir.N.Value == "1" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package converter_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDpkgQueries tests the translation of dpkg inspection commands
+func TestDpkgQueries(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		flagged  bool
+	}{
+		{
+			name:     "Package status",
+			input:    "dpkg -s libssl-dev >/dev/null 2>&1 || sudo apt install -y libssl-dev",
+			expected: "rpm -qi openssl-devel >/dev/null 2>&1 || sudo dnf install -y openssl-devel",
+		},
+		{
+			name:     "List, files and owner",
+			input:    "dpkg -l\ndpkg -L curl\ndpkg -S /usr/bin/curl",
+			expected: "rpm -qa\nrpm -ql curl\nrpm -qf /usr/bin/curl",
+		},
+		{
+			name:     "Version query",
+			input:    "VERSION=$(dpkg-query -W -f='${Version}\\n' git)",
+			expected: "VERSION=$(rpm -q --queryformat '%{VERSION}-%{RELEASE}\\n' git)",
+		},
+		{
+			name:     "Field without an rpm tag",
+			input:    `[ "$(dpkg-query -W -f='${Status}' curl)" = 'install ok installed' ] || exit 1`,
+			expected: `[ "$({ echo 'rpm has no tag for the dpkg-query field ${Status}; check installed packages with rpm -q --quiet' >&2; false; })" = 'install ok installed' ] || exit 1`,
+		},
+		{
+			name:     "Architecture",
+			input:    "ARCH=$(dpkg --print-architecture)",
+			expected: "ARCH=$(uname -m)",
+		},
+		{
+			name:     "Installed check with dpkg -l",
+			input:    "if dpkg -l | grep -q '^ii  zoxide '; then exit 0; fi",
+			expected: "if rpm -q --quiet zoxide; then exit 0; fi",
+		},
+		{
+			name:     "Negated installed check",
+			input:    "if ! dpkg -l | grep -q '^ii  zoxide\\s'; then sudo apt install -y zoxide; fi",
+			expected: "if ! rpm -q --quiet zoxide; then sudo dnf install -y zoxide; fi",
+		},
+		{
+			name:     "Installed check by prefix",
+			input:    "if ! dpkg -l | grep -q '^ii  docker'; then exit 1; fi",
+			expected: "if ! rpm -qa 'docker*' | grep -q .; then exit 1; fi",
+		},
+		{
+			name:     "Installed check by prefix of a renamed package",
+			input:    "dpkg -l | grep -q '^ii  libssl-dev' && echo present",
+			expected: "rpm -q --quiet openssl-devel && echo present",
+		},
+		{
+			name:     "Installed check with dpkg-query",
+			input:    "dpkg-query -W -f='${Status}' gnupg 2>/dev/null | grep -q 'ok installed' && echo present",
+			expected: "rpm -q --quiet gnupg2 && echo present",
+		},
+		{
+			name:     "Parsed output is flagged",
+			input:    "dpkg -l | awk '/^ii/ {print $2}' > packages.txt",
+			expected: "rpm -qa | awk '/^ii/ {print $2}' > packages.txt",
+			flagged:  true,
+		},
+		{
+			name:     "Longer pipelines are flagged once",
+			input:    "dpkg -l | grep -E '^ii' | wc -l",
+			expected: "rpm -qa | grep -E '^ii' | wc -l",
+			flagged:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converted, report := convertScript(t, tt.input)
+			assert.Equal(t, tt.expected, converted, "The dpkg command should be translated to rpm")
+
+			flags := 0
+			for _, d := range report.Diagnostics {
+				if strings.Contains(d.Message, "parsed by a pipeline") {
+					flags++
+				}
+			}
+			assert.Equal(t, tt.flagged, flags > 0, "Pipeline flag mismatch")
+			assert.LessOrEqual(t, flags, 1, "Expected the pipeline to be flagged once")
+		})
+	}
+}
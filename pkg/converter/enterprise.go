@@ -0,0 +1,151 @@
+package converter
+
+import (
+	"strconv"
+	"strings"
+)
+
+// EL9 and EL8 convert scripts for Enterprise Linux: RHEL and its rebuilds
+// CentOS Stream, AlmaLinux and Rocky Linux. Their releases share catalog
+// entries under "el". Packages missing from the base repositories come
+// from EPEL, which in turn needs CodeReady Builder (CRB).
+var (
+	EL9 Target = enterpriseLinux{release: 9}
+	EL8 Target = enterpriseLinux{release: 8}
+)
+
+type enterpriseLinux struct {
+	release int
+}
+
+func (t enterpriseLinux) ID() string   { return "el" + strconv.Itoa(t.release) }
+func (t enterpriseLinux) Name() string { return "Enterprise Linux " + strconv.Itoa(t.release) }
+
+// PackageManager is yum on EL8, where scripts traditionally call it, and
+// dnf from EL9 on. Both accept dnf's subcommands and options.
+func (t enterpriseLinux) PackageManager() string {
+	if t.release < 9 {
+		return "yum"
+	}
+	return "dnf"
+}
+
+func (enterpriseLinux) rpm()                 {}
+func (enterpriseLinux) dnf()                 {}
+func (enterpriseLinux) releaseMacro() string { return "%rhel" }
+
+// grammar spells the dnf4 commands with yum on EL8.
+func (t enterpriseLinux) grammar() *dnfGrammar {
+	if t.release < 9 {
+		return yum
+	}
+	return dnf4
+}
+
+// yum is the grammar of EL8 scripts, whose yum is dnf4 under its old name.
+var yum = &dnfGrammar{
+	versionlockPlugin: "yum install -y 'dnf-command(versionlock)'",
+	markUser:          "yum mark install",
+	markDependency:    "yum mark remove",
+	userInstalled:     "yum repoquery --userinstalled --queryformat '%{name}'",
+	automaticPackage:  "dnf-automatic",
+	automaticTimer:    "dnf-automatic.timer",
+}
+
+func (t enterpriseLinux) family() (name, release string) {
+	return "el", strconv.Itoa(t.release)
+}
+
+// osRelease returns RHEL's ID. The rebuilds set their own, such as
+// almalinux, and only list rhel in ID_LIKE.
+func (enterpriseLinux) osRelease() (id, note string) {
+	return "rhel", "only RHEL sets ID=rhel; CentOS Stream, AlmaLinux and Rocky Linux list it in ID_LIKE"
+}
+
+func (enterpriseLinux) Subcommand(tool, sub string) (string, bool) {
+	return Fedora.Subcommand(tool, sub)
+}
+
+func (enterpriseLinux) Option(flag string) (string, bool) {
+	return Fedora.Option(flag)
+}
+
+func (t enterpriseLinux) Install(sudo string, pkgs []string) string {
+	return sudo + t.PackageManager() + " install -y " + strings.Join(pkgs, " ")
+}
+
+func (t enterpriseLinux) Remove(sudo string, pkgs []string) string {
+	return sudo + t.PackageManager() + " remove -y " + strings.Join(pkgs, " ")
+}
+
+// GroupInstall uses yum's groupinstall on EL8.
+func (t enterpriseLinux) GroupInstall(sudo string, ids []string) string {
+	if t.release < 9 {
+		return sudo + "yum groupinstall -y " + strings.Join(ids, " ")
+	}
+	return sudo + "dnf group install -y " + strings.Join(ids, " ")
+}
+
+func (enterpriseLinux) Pin(name, version string) (string, bool) {
+	return Fedora.Pin(name, version)
+}
+
+// crbRepo returns the repository ID of CodeReady Builder, which EL8
+// rebuilds call PowerTools.
+func (t enterpriseLinux) crbRepo() string {
+	if t.release < 9 {
+		return "powertools"
+	}
+	return "crb"
+}
+
+// ExtraRepos enables CRB and installs epel-release. EPEL packages depend on
+// CRB, so it is enabled for them as well.
+func (t enterpriseLinux) ExtraRepos(sudo string, names []string) (string, bool) {
+	crb := containsString(names, "crb") || containsString(names, "epel")
+	if !crb {
+		return "", false
+	}
+	text := sudo + t.PackageManager() + " config-manager --set-enabled " + t.crbRepo()
+	if containsString(names, "epel") {
+		text += " && " + t.Install(sudo, []string{"epel-release"})
+	}
+	return text, true
+}
+
+// EnableRepo enables a COPR project, which builds for EPEL as well.
+func (t enterpriseLinux) EnableRepo(sudo, id string, remove bool) (string, bool) {
+	if remove {
+		return sudo + t.PackageManager() + " copr remove -y " + id, true
+	}
+	return sudo + t.PackageManager() + " copr enable -y " + id, true
+}
+
+func (t enterpriseLinux) AddRepoURL(sudo, url string) (string, bool) {
+	return sudo + t.PackageManager() + " config-manager --add-repo " + url, true
+}
+
+// RepoFile, the key commands, Arch and DevPackage work as on Fedora.
+func (enterpriseLinux) RepoFile(id string, repos []Repo) (string, string, bool) {
+	return Fedora.RepoFile(id, repos)
+}
+
+func (enterpriseLinux) ImportKey(sudo, source string) string {
+	return Fedora.ImportKey(sudo, source)
+}
+
+func (enterpriseLinux) ListKeys() string {
+	return Fedora.ListKeys()
+}
+
+func (enterpriseLinux) DeleteKey(sudo, id string) string {
+	return Fedora.DeleteKey(sudo, id)
+}
+
+func (enterpriseLinux) Arch(debian string) (string, bool) {
+	return Fedora.Arch(debian)
+}
+
+func (enterpriseLinux) DevPackage(name string) (string, bool) {
+	return Fedora.DevPackage(name)
+}
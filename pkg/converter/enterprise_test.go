@@ -0,0 +1,117 @@
+package converter_test
+
+import (
+	"testing"
+
+	"ubuntu-to-fedora/pkg/converter"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestEnterpriseLinuxTarget tests the conversion of scripts for Enterprise
+// Linux 9 and 8
+func TestEnterpriseLinuxTarget(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   converter.Target
+		input    string
+		expected string
+		warning  string
+	}{
+		{
+			name:     "Base packages",
+			target:   converter.EL9,
+			input:    "sudo apt install -y curl git libssl-dev",
+			expected: "sudo dnf install -y curl git openssl-devel",
+		},
+		{
+			name:     "yum on EL8",
+			target:   converter.EL8,
+			input:    "sudo apt-get update && sudo apt-get install -y curl",
+			expected: "sudo yum update && sudo yum install -y curl",
+		},
+		{
+			name:     "EPEL enabled once",
+			target:   converter.EL9,
+			input:    "sudo apt install -y ripgrep curl\nsudo apt install -y fzf",
+			expected: "sudo dnf config-manager --set-enabled crb && sudo dnf install -y epel-release && sudo dnf install -y ripgrep curl\nsudo dnf install -y fzf",
+		},
+		{
+			name:     "EPEL enabled in a condition",
+			target:   converter.EL9,
+			input:    "command -v rg || sudo apt install -y ripgrep",
+			expected: "command -v rg || { sudo dnf config-manager --set-enabled crb && sudo dnf install -y epel-release && sudo dnf install -y ripgrep; }",
+		},
+		{
+			name:     "PowerTools on EL8",
+			target:   converter.EL8,
+			input:    "sudo apt install -y libyaml-dev",
+			expected: "sudo yum config-manager --set-enabled powertools && sudo yum install -y libyaml-devel",
+		},
+		{
+			name:     "Package missing from the release",
+			target:   converter.EL8,
+			input:    "sudo apt install -y curl zoxide",
+			expected: "sudo yum install -y curl",
+			warning:  "zoxide is not available for Enterprise Linux 8",
+		},
+		{
+			name:     "Only packages missing from the release",
+			target:   converter.EL8,
+			input:    "sudo apt install -y zoxide",
+			expected: "{ echo 'zoxide not available for Enterprise Linux 8' >&2; false; }",
+		},
+		{
+			name:     "Holds on EL8",
+			target:   converter.EL8,
+			input:    "sudo apt-mark hold curl\napt-mark showmanual",
+			expected: "sudo yum install -y 'dnf-command(versionlock)' && sudo yum versionlock add curl\nyum repoquery --userinstalled --queryformat '%{name}'",
+		},
+		{
+			name:     "Package groups",
+			target:   converter.EL8,
+			input:    "sudo apt install -y build-essential",
+			expected: "sudo yum groupinstall -y development",
+		},
+		{
+			name:     "Vendor repository",
+			target:   converter.EL9,
+			input:    "echo \"deb [signed-by=/etc/apt/keyrings/docker.gpg] https://download.docker.com/linux/ubuntu noble stable\" | sudo tee /etc/apt/sources.list.d/docker.list",
+			expected: "echo '[docker]\nname=Docker CE\nbaseurl=https://download.docker.com/linux/centos/$releasever/$basearch/stable\nenabled=1\ngpgcheck=1\ngpgkey=https://download.docker.com/linux/centos/gpg' | sudo tee /etc/yum.repos.d/docker.repo > /dev/null",
+		},
+		{
+			name:     "PPA in the base repositories",
+			target:   converter.EL9,
+			input:    "sudo add-apt-repository -y ppa:git-core/ppa",
+			expected: "true",
+			warning:  "removed ppa:git-core/ppa: its packages are in the Enterprise Linux 9 repositories",
+		},
+		{
+			name:     "Release number",
+			target:   converter.EL9,
+			input:    "VERSION=$(lsb_release -rs)",
+			expected: "VERSION=$(rpm -E %rhel)",
+		},
+		{
+			name:     "Distribution ID",
+			target:   converter.EL9,
+			input:    `[ "$ID" = ubuntu ] && echo ok`,
+			expected: `[ "$ID" = rhel ] && echo ok`,
+			warning:  "distribution check now matches rhel: only RHEL sets ID=rhel; CentOS Stream, AlmaLinux and Rocky Linux list it in ID_LIKE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converted, report := convertScriptFor(t, tt.input, tt.target)
+			assert.Equal(t, tt.expected, converted, "Commands should be converted for Enterprise Linux")
+			if tt.warning != "" {
+				var messages []string
+				for _, d := range report.Diagnostics {
+					messages = append(messages, d.Message)
+				}
+				assert.Contains(t, messages, tt.warning, "The conversion should be reported")
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Fedora converts scripts for Fedora, which installs packages with dnf.
+// The commands use dnf4 syntax; FedoraDNF5 covers Fedora 41 and later.
+var Fedora Target = fedora{}
+
+type fedora struct{}
+
+// aptSubcommands maps apt and apt-get subcommands to dnf's where the names
+// differ.
+var aptSubcommands = map[string]string{
+	"show":         "info",
+	"purge":        "remove",
+	"dist-upgrade": "upgrade",
+	"full-upgrade": "upgrade",
+	"depends":      "repoquery --requires",
+	"rdepends":     "repoquery --whatrequires",
+}
+
+// aptCacheSubcommands maps apt-cache subcommands to dnf. policy without
+// packages, which lists the repositories, is looked up as policy-repos.
+var aptCacheSubcommands = map[string]string{
+	"search":       "search",
+	"show":         "info",
+	"showpkg":      "info",
+	"madison":      "repoquery --showduplicates --queryformat '%{name} | %{evr} | %{repoid}'",
+	"depends":      "repoquery --requires",
+	"rdepends":     "repoquery --whatrequires",
+	"pkgnames":     "repoquery --queryformat '%{name}'",
+	"policy":       "list --showduplicates",
+	"policy-repos": "repolist",
+}
+
+// dnfOptions maps apt options to their dnf equivalents. An empty value
+// means dnf has no counterpart and the option is dropped with a warning.
+var dnfOptions = map[string]string{
+	"-y":                           "-y",
+	"--yes":                        "-y",
+	"--assume-yes":                 "-y",
+	"-q":                           "-q",
+	"-qq":                          "-q",
+	"--quiet":                      "-q",
+	"--no-install-recommends":      "--setopt=install_weak_deps=False",
+	"--install-recommends":         "--setopt=install_weak_deps=True",
+	"--allow-unauthenticated":      "--nogpgcheck",
+	"-d":                           "--downloadonly",
+	"--download-only":              "--downloadonly",
+	"-s":                           "--assumeno",
+	"--simulate":                   "--assumeno",
+	"--dry-run":                    "--assumeno",
+	"-m":                           "--skip-broken",
+	"--fix-missing":                "--skip-broken",
+	"--installed":                  "--installed",
+	"--upgradable":                 "--upgrades",
+	"-a":                           "--showduplicates",
+	"--all-versions":               "--showduplicates",
+	"-f":                           "",
+	"--fix-broken":                 "",
+	"--allow-downgrades":           "",
+	"--allow-remove-essential":     "",
+	"--allow-change-held-packages": "",
+	"--no-install-suggests":        "",
+	"--no-upgrade":                 "",
+	"--only-upgrade":               "",
+	"--reinstall":                  "",
+	"--purge":                      "",
+	"-o":                           "",
+	"--option":                     "",
+	"-t":                           "",
+	"--target-release":             "",
+	"-c":                           "",
+	"--config-file":                "",
+}
+
+// rpmFusionReleases are the packages enabling the RPM Fusion repositories.
+var rpmFusionReleases = map[string]string{
+	"rpmfusion-free":    "https://mirrors.rpmfusion.org/free/fedora/rpmfusion-free-release-$(rpm -E %fedora).noarch.rpm",
+	"rpmfusion-nonfree": "https://mirrors.rpmfusion.org/nonfree/fedora/rpmfusion-nonfree-release-$(rpm -E %fedora).noarch.rpm",
+}
+
+// dnfGrammar holds the spellings of the dnf commands that changed between
+// dnf4 and dnf5.
+type dnfGrammar struct {
+	versionlockPlugin string // installs the versionlock command, if it is a plugin
+	markUser          string // marks packages as installed by the user
+	markDependency    string // marks packages as dependencies
+	userInstalled     string // lists the packages installed by the user
+	automaticPackage  string
+	automaticConfig   string // creates the automatic.conf to edit, if needed
+	automaticTimer    string
+}
+
+// dnf4 is the grammar of dnf up to Fedora 40 and on Enterprise Linux.
+var dnf4 = &dnfGrammar{
+	versionlockPlugin: "dnf install -y 'dnf-command(versionlock)'",
+	markUser:          "dnf mark install",
+	markDependency:    "dnf mark remove",
+	userInstalled:     "dnf repoquery --userinstalled --queryformat '%{name}'",
+	automaticPackage:  "dnf-automatic",
+	automaticTimer:    "dnf-automatic.timer",
+}
+
+// rpmArches maps Debian architecture names to their RPM spelling.
+var rpmArches = map[string]string{
+	"amd64": "x86_64",
+	"arm64": "aarch64",
+	"armhf": "armv7hl",
+	"i386":  "i686",
+}
+
+func (fedora) ID() string             { return "fedora" }
+func (fedora) Name() string           { return "Fedora" }
+func (fedora) PackageManager() string { return "dnf" }
+func (fedora) rpm()                   {}
+func (fedora) dnf()                   {}
+func (fedora) releaseMacro() string   { return "%fedora" }
+func (fedora) grammar() *dnfGrammar   { return dnf4 }
+
+func (fedora) Subcommand(tool, sub string) (string, bool) {
+	if tool == "apt-cache" {
+		dnf, ok := aptCacheSubcommands[sub]
+		return dnf, ok
+	}
+	if dnf, ok := aptSubcommands[sub]; ok {
+		return dnf, true
+	}
+	return sub, true
+}
+
+func (fedora) Option(flag string) (string, bool) {
+	dnf, ok := dnfOptions[flag]
+	return dnf, ok
+}
+
+func (fedora) Install(sudo string, pkgs []string) string {
+	return sudo + "dnf install -y " + strings.Join(pkgs, " ")
+}
+
+func (fedora) Remove(sudo string, pkgs []string) string {
+	return sudo + "dnf remove -y " + strings.Join(pkgs, " ")
+}
+
+func (fedora) GroupInstall(sudo string, ids []string) string {
+	return sudo + "dnf group install -y " + strings.Join(ids, " ")
+}
+
+func (fedora) Pin(name, version string) (string, bool) {
+	return name + "-" + version, true
+}
+
+// ExtraRepos installs the release packages of RPM Fusion.
+func (fedora) ExtraRepos(sudo string, names []string) (string, bool) {
+	var urls []string
+	for _, name := range names {
+		if url, ok := rpmFusionReleases[name]; ok {
+			urls = append(urls, url)
+		}
+	}
+	if len(urls) == 0 {
+		return "", false
+	}
+	return Fedora.Install(sudo, urls), true
+}
+
+// EnableRepo enables a COPR project.
+func (fedora) EnableRepo(sudo, id string, remove bool) (string, bool) {
+	if remove {
+		return sudo + "dnf copr remove -y " + id, true
+	}
+	return sudo + "dnf copr enable -y " + id, true
+}
+
+func (fedora) AddRepoURL(sudo, url string) (string, bool) {
+	return sudo + "dnf config-manager --add-repo " + url, true
+}
+
+func (fedora) RepoFile(id string, repos []Repo) (string, string, bool) {
+	sections := make([]string, len(repos))
+	for i, repo := range repos {
+		lines := []string{
+			"[" + repo.ID + "]",
+			"name=" + repo.Name,
+			"baseurl=" + repo.BaseURL,
+			"enabled=1",
+			"gpgcheck=1",
+		}
+		if repo.GPGKey != "" {
+			lines = append(lines, "gpgkey="+repo.GPGKey)
+		}
+		sections[i] = strings.Join(lines, "\n")
+	}
+	return fmt.Sprintf("/etc/yum.repos.d/%s.repo", id), strings.Join(sections, "\n\n"), true
+}
+
+func (fedora) ImportKey(sudo, source string) string {
+	return sudo + "rpm --import " + source
+}
+
+func (fedora) ListKeys() string {
+	return "rpm -q gpg-pubkey"
+}
+
+// DeleteKey removes a key by the short ID rpm names its gpg-pubkey
+// package after, the last eight hex digits of the fingerprint.
+func (fedora) DeleteKey(sudo, id string) string {
+	id = strings.ToLower(id)
+	if len(id) > 8 {
+		id = id[len(id)-8:]
+	}
+	return sudo + "rpm -e gpg-pubkey-" + id
+}
+
+func (fedora) Arch(debian string) (string, bool) {
+	arch, ok := rpmArches[debian]
+	return arch, ok
+}
+
+// DevPackage drops the lib prefix, as Fedora names development packages
+// after the project: libfoo-dev becomes foo-devel.
+func (fedora) DevPackage(name string) (string, bool) {
+	base := strings.TrimSuffix(name, "-dev")
+	if trimmed := strings.TrimPrefix(base, "lib"); trimmed != "" {
+		base = trimmed
+	}
+	return base + "-devel", true
+}
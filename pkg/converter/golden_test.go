@@ -0,0 +1,92 @@
+package converter_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ubuntu-to-fedora/pkg/converter"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "rewrite the expected output of golden tests")
+
+// TestGolden converts each testdata/*.ubuntu.sh script and compares the
+// result with the matching .fedora.sh file. Scripts with a file named after
+// another target, such as .opensuse-tumbleweed.sh, are also converted for
+// that target. Run with -update to rewrite the expected files after an
+// intended change.
+func TestGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "*.ubuntu.sh"))
+	if err != nil {
+		t.Fatalf("Failed to list golden inputs: %v", err)
+	}
+	if len(inputs) == 0 {
+		t.Fatal("No golden inputs found")
+	}
+
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".ubuntu.sh")
+		for _, target := range converter.Targets() {
+			goldenPath := strings.TrimSuffix(input, ".ubuntu.sh") + "." + target.ID() + ".sh"
+			if _, err := os.Stat(goldenPath); err != nil && target != converter.Fedora {
+				continue
+			}
+			t.Run(name+"/"+target.ID(), func(t *testing.T) {
+				script, err := os.ReadFile(input)
+				if err != nil {
+					t.Fatalf("Failed to read golden input: %v", err)
+				}
+				converted, _ := convertScriptFor(t, string(script), target)
+
+				if *update {
+					if err := os.WriteFile(goldenPath, []byte(converted), 0644); err != nil {
+						t.Fatalf("Failed to update golden file: %v", err)
+					}
+				}
+				expected, err := os.ReadFile(goldenPath)
+				if err != nil {
+					t.Fatalf("Failed to read golden file: %v", err)
+				}
+				assert.Equal(t, string(expected), converted, "Converted script should match %s", goldenPath)
+			})
+		}
+	}
+}
+
+// TestReverseGolden converts each testdata/reverse/*.fedora.sh script for
+// Ubuntu and compares the result with the matching .ubuntu.sh file
+func TestReverseGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "reverse", "*.fedora.sh"))
+	if err != nil {
+		t.Fatalf("Failed to list golden inputs: %v", err)
+	}
+	if len(inputs) == 0 {
+		t.Fatal("No golden inputs found")
+	}
+
+	for _, input := range inputs {
+		goldenPath := strings.TrimSuffix(input, ".fedora.sh") + ".ubuntu.sh"
+		t.Run(filepath.Base(input), func(t *testing.T) {
+			script, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatalf("Failed to read golden input: %v", err)
+			}
+			converted, _ := convertFedoraScript(t, string(script))
+
+			if *update {
+				if err := os.WriteFile(goldenPath, []byte(converted), 0644); err != nil {
+					t.Fatalf("Failed to update golden file: %v", err)
+				}
+			}
+			expected, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("Failed to read golden file: %v", err)
+			}
+			assert.Equal(t, string(expected), converted, "Converted script should match %s", goldenPath)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package converter
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+//go:embed data/groups.json
+var groupsJSON []byte
+
+// groupCatalog maps Debian meta-packages and tasksel tasks to package
+// groups. For each distro key the value is a space-separated list of group
+// IDs; "<distro>_repos" lists the optional repositories the groups need.
+// Conversions from Fedora map group IDs back to the meta-package not
+// marked "fedora_alias".
+type groupCatalog struct {
+	Groups map[string]map[string]string `json:"groups"`
+}
+
+var groups groupCatalog
+
+func init() {
+	mustUnmarshal("groups.json", groupsJSON, &groups)
+}
+
+// translateGroup returns the target's groups for a meta-package and the
+// repositories they need. ok is false for ordinary packages.
+func (r *rewriter) translateGroup(name string) (ids, repos []string, ok bool) {
+	entry, found := groups.Groups[name]
+	if !found {
+		return nil, nil, false
+	}
+	mapped, found := catalogValue(r.target, entry, "")
+	if !found {
+		return nil, nil, false
+	}
+	extra, _ := catalogValue(r.target, entry, "_repos")
+	return strings.Fields(mapped), strings.Fields(extra), true
+}
+
+// installGroups is the conversion stage for meta-packages, run before
+// package names are mapped. Meta-package operands of an install become a
+// group install preceding the command, and the returned invocation
+// holds the remaining operands for name mapping. When every operand was a
+// meta-package the whole command is replaced. Meta-packages mapped to no
+// groups are not needed on the target and are removed. Version pins of
+// meta-packages are dropped, as groups have no versions.
+func (r *rewriter) installGroups(inv aptInvocation) aptInvocation {
+	var ids, repos []string
+	var rest, unneeded []*syntax.Word
+	for _, operand := range inv.operands {
+		name, _, pinned := strings.Cut(operand.Lit(), "=")
+		groupIDs, groupRepos, ok := r.translateGroup(name)
+		if !ok {
+			rest = append(rest, operand)
+			continue
+		}
+		if len(groupIDs) == 0 {
+			unneeded = append(unneeded, operand)
+			continue
+		}
+		if pinned {
+			r.warn(operand, "dropped the version pin of %s: %s installs it as a package group", operand.Lit(), r.target.Name())
+		}
+		for _, id := range groupIDs {
+			if !containsString(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+		for _, repo := range groupRepos {
+			if !containsString(repos, repo) {
+				repos = append(repos, repo)
+			}
+		}
+	}
+	if len(ids) == 0 && len(unneeded) == 0 {
+		return inv
+	}
+	if len(unneeded) > 0 {
+		r.warn(inv.call, "removed %s: no packages are needed on %s", r.wordsText(unneeded), r.target.Name())
+	}
+
+	text := "true"
+	if len(ids) > 0 {
+		text = r.groupInstall(inv.command, ids, repos)
+	}
+	if len(rest) == 0 {
+		r.replace(inv.call, text)
+		inv.operands = nil
+		return inv
+	}
+	for _, operand := range inv.operands {
+		if !containsWord(rest, operand) {
+			r.removeArg(inv.call, operand)
+		}
+	}
+	if len(ids) > 0 {
+		pos := inv.call.Pos().Offset()
+		r.edits = append(r.edits, edit{start: pos, end: pos, text: text + " && "})
+	}
+	inv.operands = rest
+	return inv
+}
+
+// groupInstall returns a group install of ids, run with the privilege of
+// cmd. Repositories the groups need are enabled first, once per script.
+func (r *rewriter) groupInstall(cmd command, ids, repos []string) string {
+	prefix := r.sudoText(cmd)
+	text := r.target.GroupInstall(prefix, ids)
+	if enable, ok := r.enableRepos(prefix, repos); ok {
+		text = enable + " && " + text
+	}
+	return text
+}
+
+// enableRepos returns the command enabling the optional repositories
+// among names that no earlier command of the script enabled.
+func (r *rewriter) enableRepos(sudo string, names []string) (string, bool) {
+	var pending []string
+	for _, name := range names {
+		if !r.enabledRepos[name] && !containsString(pending, name) {
+			pending = append(pending, name)
+		}
+	}
+	if len(pending) == 0 {
+		return "", false
+	}
+	text, ok := r.target.ExtraRepos(sudo, pending)
+	if !ok {
+		return "", false
+	}
+	if r.enabledRepos == nil {
+		r.enabledRepos = make(map[string]bool)
+	}
+	for _, name := range pending {
+		r.enabledRepos[name] = true
+	}
+	return text, true
+}
+
+// rewriteTasksel converts tasksel installs of desktop and other tasks to
+// group installs.
+func (r *rewriter) rewriteTasksel(cmd command) {
+	var ids, repos []string
+	install := false
+	for _, arg := range cmd.args {
+		lit := arg.Lit()
+		switch {
+		case lit == "install":
+			install = true
+		case strings.HasPrefix(lit, "-"):
+		case install:
+			groupIDs, groupRepos, ok := r.translateGroup(lit)
+			if !ok {
+				r.stub(cmd, fmt.Sprintf("no %s group known for the %s task", r.target.Name(), lit))
+				return
+			}
+			ids = append(ids, groupIDs...)
+			repos = append(repos, groupRepos...)
+		}
+	}
+	if len(ids) == 0 {
+		r.stub(cmd, fmt.Sprintf("cannot convert tasksel %s", r.wordsText(cmd.args)))
+		return
+	}
+	r.replace(cmd.call, r.groupInstall(cmd, ids, repos))
+}
+
+func containsWord(list []*syntax.Word, w *syntax.Word) bool {
+	for _, item := range list {
+		if item == w {
+			return true
+		}
+	}
+	return false
+}
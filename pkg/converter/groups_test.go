@@ -0,0 +1,67 @@
+package converter_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPackageGroups tests the translation of meta-packages to dnf groups
+func TestPackageGroups(t *testing.T) {
+	rpmFusion := "sudo dnf install -y https://mirrors.rpmfusion.org/free/fedora/rpmfusion-free-release-$(rpm -E %fedora).noarch.rpm" +
+		" https://mirrors.rpmfusion.org/nonfree/fedora/rpmfusion-nonfree-release-$(rpm -E %fedora).noarch.rpm && "
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Only meta-packages",
+			input:    "sudo apt install -y build-essential",
+			expected: "sudo dnf group install -y development-tools c-development",
+		},
+		{
+			name:     "Meta-packages mixed with packages",
+			input:    "sudo apt-get install -y curl build-essential git",
+			expected: "sudo dnf group install -y development-tools c-development && sudo dnf install -y curl git",
+		},
+		{
+			name:     "Groups needing RPM Fusion",
+			input:    "sudo apt install -y ubuntu-restricted-extras\nsudo apt install -y xubuntu-restricted-extras vlc",
+			expected: rpmFusion + "sudo dnf group install -y multimedia\nsudo dnf group install -y multimedia && sudo dnf install -y vlc",
+		},
+		{
+			name:     "Meta-package with dropped packages",
+			input:    "sudo apt install -y build-essential apt-transport-https",
+			expected: "sudo dnf group install -y development-tools c-development && true",
+		},
+		{
+			name:     "Negated install",
+			input:    "if ! sudo apt install -y ubuntu-restricted-extras; then exit 1; fi",
+			expected: "if ! { " + rpmFusion + "sudo dnf group install -y multimedia; }; then exit 1; fi",
+		},
+		{
+			name:     "Piped install",
+			input:    "yes | sudo apt install build-essential curl | tee install.log",
+			expected: "yes | { sudo dnf group install -y development-tools c-development && sudo dnf install curl; } | tee install.log",
+		},
+		{
+			name:     "Redirected install",
+			input:    "sudo apt install -y build-essential git > /dev/null",
+			expected: "{ sudo dnf group install -y development-tools c-development && sudo dnf install -y git; } > /dev/null",
+		},
+		{
+			name:     "tasksel tasks",
+			input:    "sudo apt install -y tasksel\nsudo tasksel install kubuntu-desktop",
+			expected: "true\nsudo dnf group install -y kde-desktop",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converted, _ := convertScript(t, tt.input)
+			assert.Equal(t, tt.expected, converted, "Meta-packages should become dnf groups")
+		})
+	}
+}
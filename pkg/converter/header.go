@@ -0,0 +1,182 @@
+package converter
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Version is the converter version recorded in converted scripts. Release
+// builds set it with -ldflags "-X ubuntu-to-fedora/pkg/converter.Version=...".
+var Version = "dev"
+
+// rulesVersion is bumped whenever the conversion rules change the output
+// for the same input. Changes to the embedded data files are picked up
+// through their digest.
+const rulesVersion = "1"
+
+const headerPrefix = "# Converted by ubuntu-to-fedora:"
+
+// RuleSetVersion identifies the conversion rules, the package table version
+// and the data table entries used for the target with the given ID, so
+// scripts converted by older rules can be told apart. Entries for other targets and for Nix
+// modules are left out, so editing them does not affect other targets.
+func RuleSetVersion(id string) string {
+	keys := []string{id}
+	if t, ok := LookupTarget(id); ok {
+		if f, ok := t.(familyTarget); ok {
+			name, _ := f.family()
+			keys = append(keys, name)
+		}
+	} else if id == ubuntuID {
+		// Conversions from Fedora read the Fedora entries backwards.
+		keys = []string{Fedora.ID()}
+	}
+
+	owners := catalogOwners()
+	h := sha256.New()
+	// The alternatives table only has Fedora entries, which every dnf
+	// target reads.
+	h.Write(alternativesJSON)
+	for _, data := range [][]byte{packagesJSON, ppasJSON, reposJSON, debsJSON, snapsJSON, groupsJSON} {
+		var table interface{}
+		mustUnmarshal("data file", data, &table)
+		filtered, err := json.Marshal(catalogEntries(table, keys, owners))
+		if err != nil {
+			panic(fmt.Sprintf("cannot digest embedded data: %v", err))
+		}
+		h.Write(filtered)
+	}
+	return rulesVersion + "." + PackageMapVersion() + "-" + hex.EncodeToString(h.Sum(nil))[:8]
+}
+
+// catalogEntries returns a data table with only the entry fields read for
+// a target whose own keys are keys, out of those owned by a target, family
+// or output format. Fields of other targets, such as
+// "arch" or "el_repos", and "nixpkgs" are dropped; fields shared by all
+// targets, such as "pattern" or "flatpak", are kept.
+func catalogEntries(table interface{}, keys []string, owners map[string]bool) interface{} {
+	switch table := table.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(table))
+		for k, v := range table {
+			if _, isString := v.(string); isString {
+				base, _, _ := strings.Cut(k, "_")
+				if !containsString(keys, base) && owners[base] {
+					continue
+				}
+			}
+			if entry := catalogEntries(v, keys, owners); entry != nil {
+				out[k] = entry
+			}
+		}
+		if len(out) == 0 && len(table) > 0 {
+			// Entries with no fields for the target read as missing.
+			return nil
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(table))
+		for i, v := range table {
+			out[i] = catalogEntries(v, keys, owners)
+		}
+		return out
+	}
+	return table
+}
+
+// catalogOwners returns the keys of data file fields that belong to one
+// target, family or output format.
+func catalogOwners() map[string]bool {
+	owners := map[string]bool{"nixpkgs": true}
+	for _, t := range Targets() {
+		owners[t.ID()] = true
+		if f, ok := t.(familyTarget); ok {
+			name, _ := f.family()
+			owners[name] = true
+		}
+	}
+	return owners
+}
+
+// header is the machine-readable line recording how a script was
+// converted: the tool and rule-set versions, hashes of the original
+// script and of the converted output without the header, and the target.
+// Headers without a target come from conversions for Fedora.
+type header struct {
+	tool   string
+	rules  string
+	source string
+	output string
+	target string
+}
+
+func (h header) String() string {
+	return fmt.Sprintf("%s tool=%s rules=%s source=sha256:%s output=sha256:%s target=%s",
+		headerPrefix, h.tool, h.rules, h.source, h.output, h.target)
+}
+
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// parseHeader finds the conversion header on the first line of content, or
+// on the second after a shebang, and returns it with content minus the
+// header line.
+func parseHeader(content []byte) (h header, body []byte, ok bool) {
+	lines := bytes.SplitAfterN(content, []byte("\n"), 3)
+	i := 0
+	if len(lines) > 1 && bytes.HasPrefix(lines[0], []byte("#!")) {
+		i = 1
+	}
+	line := strings.TrimRight(string(lines[i]), "\n")
+	if !strings.HasPrefix(line, headerPrefix) {
+		return header{}, content, false
+	}
+
+	h.target = Fedora.ID()
+	for _, field := range strings.Fields(strings.TrimPrefix(line, headerPrefix)) {
+		key, value, _ := strings.Cut(field, "=")
+		value = strings.TrimPrefix(value, "sha256:")
+		switch key {
+		case "tool":
+			h.tool = value
+		case "rules":
+			h.rules = value
+		case "source":
+			h.source = value
+		case "output":
+			h.output = value
+		case "target":
+			h.target = value
+		}
+	}
+	body = append(bytes.Join(lines[:i], nil), bytes.Join(lines[i+1:], nil)...)
+	return h, body, true
+}
+
+// withHeader inserts h into a converted script, after its shebang if it
+// has one.
+func withHeader(content []byte, h header) []byte {
+	var out bytes.Buffer
+	rest := content
+	if bytes.HasPrefix(content, []byte("#!")) {
+		end := bytes.IndexByte(content, '\n')
+		if end < 0 {
+			out.Write(content)
+			out.WriteByte('\n')
+			rest = nil
+		} else {
+			out.Write(content[:end+1])
+			rest = content[end+1:]
+		}
+	}
+	out.WriteString(h.String())
+	out.WriteByte('\n')
+	out.Write(rest)
+	return out.Bytes()
+}
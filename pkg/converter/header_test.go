@@ -0,0 +1,103 @@
+package converter_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ubuntu-to-fedora/pkg/converter"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestConversionHeader tests that repeated conversions are safe
+func TestConversionHeader(t *testing.T) {
+	tempDir := t.TempDir()
+	scriptPath := filepath.Join(tempDir, "tools.sh")
+	original := "#!/bin/bash\nsudo apt install -y curl\n"
+	if err := os.WriteFile(scriptPath, []byte(original), 0644); err != nil {
+		t.Fatalf("Failed to write test script: %v", err)
+	}
+
+	convert := func() converter.Report {
+		t.Helper()
+		reports, err := converter.ConvertDir(tempDir)
+		if err != nil {
+			t.Fatalf("Failed to convert: %v", err)
+		}
+		return reports[0]
+	}
+	read := func() string {
+		t.Helper()
+		content, err := os.ReadFile(scriptPath)
+		if err != nil {
+			t.Fatalf("Failed to read script: %v", err)
+		}
+		return string(content)
+	}
+
+	report := convert()
+	assert.True(t, report.Modified, "Expected the script to be converted")
+	converted := read()
+	lines := strings.Split(converted, "\n")
+	assert.Equal(t, "#!/bin/bash", lines[0], "The shebang should stay first")
+	assert.True(t, strings.HasPrefix(lines[1], "# Converted by ubuntu-to-fedora: tool="+converter.Version+" rules="+converter.RuleSetVersion("fedora")+" source=sha256:"),
+		"Expected a conversion header, got %q", lines[1])
+	assert.Contains(t, converter.RuleSetVersion("fedora"), "."+converter.PackageMapVersion()+"-",
+		"Expected the rule set to name the package table version")
+	assert.Equal(t, "sudo dnf install -y curl", lines[2])
+
+	t.Run("Unchanged file is skipped", func(t *testing.T) {
+		report := convert()
+		assert.True(t, report.AlreadyConverted, "Expected the script to be reported as converted")
+		assert.False(t, report.Modified)
+		assert.Equal(t, converted, read(), "The script should not change")
+	})
+
+	t.Run("Older rules are reported", func(t *testing.T) {
+		defer func(v string) { converter.Version = v }(converter.Version)
+		converter.Version = "next"
+
+		report := convert()
+		assert.True(t, report.Stale, "Expected the script to be reported as converted by older rules")
+		assert.False(t, report.AlreadyConverted)
+		assert.False(t, report.Modified)
+		assert.Equal(t, converted, read(), "The header should not claim the new rules")
+	})
+
+	t.Run("Restored source is reconverted", func(t *testing.T) {
+		defer func(v string) { converter.Version = v }(converter.Version)
+		converter.Version = "next"
+
+		header := strings.SplitAfterN(converted, "\n", 3)[1]
+		restored := "#!/bin/bash\n" + header + "sudo apt install -y curl\n"
+		if err := os.WriteFile(scriptPath, []byte(restored), 0644); err != nil {
+			t.Fatalf("Failed to restore script: %v", err)
+		}
+		report := convert()
+		assert.True(t, report.Modified, "Expected the original script to be converted again")
+		assert.Contains(t, read(), "tool=next ", "The header should record the new conversion")
+		assert.Contains(t, read(), "sudo dnf install -y curl")
+	})
+
+	t.Run("Hand edits are reported", func(t *testing.T) {
+		edited := converted + "echo done\n"
+		if err := os.WriteFile(scriptPath, []byte(edited), 0644); err != nil {
+			t.Fatalf("Failed to edit script: %v", err)
+		}
+		report := convert()
+		assert.True(t, report.HandEdited, "Expected the edit to be reported")
+		assert.False(t, report.Modified)
+		assert.Equal(t, edited, read(), "Hand-edited scripts should be left alone")
+	})
+
+	t.Run("Changed source is reconverted", func(t *testing.T) {
+		if err := os.WriteFile(scriptPath, []byte("#!/bin/bash\nsudo apt install -y git\n"), 0644); err != nil {
+			t.Fatalf("Failed to update script: %v", err)
+		}
+		report := convert()
+		assert.True(t, report.Modified, "Expected the new source to be converted")
+		assert.Contains(t, read(), "sudo dnf install -y git")
+	})
+}
@@ -0,0 +1,348 @@
+package converter
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// keyringDirs are where apt-era scripts store repository signing keys.
+var keyringDirs = []string{
+	"/usr/share/keyrings/",
+	"/etc/apt/keyrings/",
+	"/etc/apt/trusted.gpg.d/",
+}
+
+// keyring is a signing key a script downloads into a keyring file. The
+// statement doing so is rewritten once the whole script has been seen:
+// keys referenced by a generated .repo file become its gpgkey entry,
+// all others are imported into the target's keyring.
+type keyring struct {
+	url  *syntax.Word
+	node syntax.Node
+	used bool
+}
+
+// pipeline flattens a pipe chain into its statements, in order.
+func pipeline(stmt *syntax.Stmt) []*syntax.Stmt {
+	if pipe, ok := stmt.Cmd.(*syntax.BinaryCmd); ok && (pipe.Op == syntax.Pipe || pipe.Op == syntax.PipeAll) {
+		return append(pipeline(pipe.X), pipeline(pipe.Y)...)
+	}
+	return []*syntax.Stmt{stmt}
+}
+
+// pipelineRange spans the commands of a pipeline, leaving out the ! that
+// negates it.
+type pipelineRange struct {
+	*syntax.Stmt
+}
+
+func (p *pipelineRange) Pos() syntax.Pos {
+	return pipeline(p.Stmt)[0].Cmd.Pos()
+}
+
+func (p *pipelineRange) End() syntax.Pos {
+	return p.Cmd.End()
+}
+
+func isKeyringPath(p string) bool {
+	for _, dir := range keyringDirs {
+		if strings.HasPrefix(p, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// downloadURL returns the http(s) URL fetched by a curl or wget command
+// and the file it is saved to, if the command names one.
+func (r *rewriter) downloadURL(cmd command) (rawURL *syntax.Word, output string, ok bool) {
+	name := cmd.name.Lit()
+	if name != "curl" && name != "wget" {
+		return nil, "", false
+	}
+	for i := 0; i < len(cmd.args); i++ {
+		value, _ := r.template(cmd.args[i])
+		switch {
+		case strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://"):
+			rawURL = cmd.args[i]
+		case isOutputFlag(name, value) && i+1 < len(cmd.args):
+			i++
+			output, _ = r.template(cmd.args[i])
+		case name == "curl" && strings.HasPrefix(value, "--output="):
+			output = strings.TrimPrefix(value, "--output=")
+		case name == "wget" && strings.HasPrefix(value, "--output-document="):
+			output = strings.TrimPrefix(value, "--output-document=")
+		}
+	}
+	if output == "-" {
+		output = ""
+	}
+	return rawURL, output, rawURL != nil
+}
+
+// isOutputFlag reports whether flag names the output file, including
+// clusters like curl's -fsSLo and wget's -qO.
+func isOutputFlag(name, flag string) bool {
+	if strings.HasPrefix(flag, "--") {
+		return (name == "curl" && flag == "--output") || (name == "wget" && flag == "--output-document")
+	}
+	if !strings.HasPrefix(flag, "-") {
+		return false
+	}
+	if name == "curl" {
+		return strings.HasSuffix(flag, "o")
+	}
+	return strings.HasSuffix(flag, "O")
+}
+
+// rewriteKeyImport handles statements that fetch a signing key and either
+// hand it to apt-key or store it in a keyring file, with or without
+// gpg --dearmor in between. It reports whether stmt was handled.
+func (r *rewriter) rewriteKeyImport(stmt *syntax.Stmt) bool {
+	stages := pipeline(stmt)
+	first, ok := stmtCommand(stages[0])
+	if !ok {
+		return false
+	}
+	keyURL, output, ok := r.downloadURL(first)
+	if !ok {
+		return false
+	}
+
+	node := syntax.Node(&pipelineRange{stmt})
+	if len(stages) == 1 {
+		node = &stmtRange{stmt}
+		if output == "" || !isKeyringPath(output) {
+			output, _ = r.redirectPath(stmt)
+		}
+		if !isKeyringPath(output) {
+			return false
+		}
+		r.trackKeyring(output, keyURL, node)
+		return true
+	}
+
+	dearmor := false
+	for _, stage := range stages[1:] {
+		cmd, ok := stmtCommand(stage)
+		if !ok {
+			return false
+		}
+		switch cmd.name.Lit() {
+		case "apt-key":
+			r.replace(node, r.target.ImportKey("sudo ", r.text(keyURL)))
+			return true
+		case "gpg":
+			if !hasArg(cmd, "--dearmor") {
+				return false
+			}
+			dearmor = true
+			for i, arg := range cmd.args {
+				if (arg.Lit() == "-o" || arg.Lit() == "--output") && i+1 < len(cmd.args) {
+					output, _ = r.template(cmd.args[i+1])
+				}
+			}
+		case "tee":
+			for _, arg := range cmd.args {
+				if !strings.HasPrefix(arg.Lit(), "-") {
+					output, _ = r.template(arg)
+					break
+				}
+			}
+		default:
+			return false
+		}
+		if path, ok := r.redirectPath(stage); ok {
+			output = path
+		}
+	}
+
+	if output == "" || (!dearmor && !isKeyringPath(output)) {
+		return false
+	}
+	r.trackKeyring(output, keyURL, node)
+	return true
+}
+
+func (r *rewriter) trackKeyring(path string, keyURL *syntax.Word, node syntax.Node) {
+	if r.keyrings == nil {
+		r.keyrings = make(map[string]*keyring)
+	}
+	r.keyrings[path] = &keyring{url: keyURL, node: node}
+	r.keyringOrder = append(r.keyringOrder, path)
+}
+
+// rewriteKeyringCopy follows install, cp and mv commands copying a tracked
+// keyring file, so repositories signed by the copy still find the key. The
+// copy is dropped, as the download no longer writes the file it copies.
+func (r *rewriter) rewriteKeyringCopy(cmd command) {
+	var files []string
+	var dir, dest string
+	for i := 0; i < len(cmd.args); i++ {
+		lit := cmd.args[i].Lit()
+		switch {
+		case lit == "-t" && i+1 < len(cmd.args):
+			i++
+			dir, _ = r.template(cmd.args[i])
+		case cmd.name.Lit() == "install" && (lit == "-o" || lit == "-g" || lit == "-m" || lit == "-S") && i+1 < len(cmd.args):
+			i++
+		case strings.HasPrefix(lit, "-"):
+		default:
+			value, _ := r.template(cmd.args[i])
+			files = append(files, value)
+		}
+	}
+	if dir == "" {
+		if len(files) < 2 {
+			return
+		}
+		last := files[len(files)-1]
+		files = files[:len(files)-1]
+		if len(files) > 1 || strings.HasSuffix(last, "/") || isKeyringDir(last) {
+			dir = last
+		} else {
+			dest = last
+		}
+	}
+	if len(files) == 0 {
+		return
+	}
+	for _, src := range files {
+		if r.keyrings[src] == nil {
+			return
+		}
+	}
+
+	for _, src := range files {
+		to := dest
+		if dir != "" {
+			to = path.Join(dir, path.Base(src))
+		}
+		r.keyrings[to] = r.keyrings[src]
+	}
+	r.replace(cmd.call, "true")
+}
+
+// isKeyringDir reports whether dir is one of the keyring directories,
+// given without its trailing slash.
+func isKeyringDir(dir string) bool {
+	for _, d := range keyringDirs {
+		if dir+"/" == d {
+			return true
+		}
+	}
+	return false
+}
+
+// useKeyring returns the URL of the key stored at path, marking it as
+// referenced by a generated .repo file.
+func (r *rewriter) useKeyring(path string) (string, bool) {
+	k, ok := r.keyrings[path]
+	if !ok {
+		return "", false
+	}
+	k.used = true
+	keyURL, _ := r.template(k.url)
+	return keyURL, true
+}
+
+// finishKeyrings rewrites the keyring downloads once every reference to
+// them is known.
+func (r *rewriter) finishKeyrings() {
+	for _, path := range r.keyringOrder {
+		k := r.keyrings[path]
+		if k.used {
+			r.replace(k.node, "true")
+			continue
+		}
+		r.replace(k.node, r.target.ImportKey("sudo ", r.text(k.url)))
+	}
+}
+
+// redirectPath returns the file stmt's output is redirected to.
+func (r *rewriter) redirectPath(stmt *syntax.Stmt) (string, bool) {
+	for _, redir := range stmt.Redirs {
+		if redir.Op == syntax.RdrOut || redir.Op == syntax.AppOut || redir.Op == syntax.ClbOut {
+			target, _ := r.template(redir.Word)
+			if target != "/dev/null" {
+				return target, true
+			}
+		}
+	}
+	return "", false
+}
+
+func hasArg(cmd command, lit string) bool {
+	for _, arg := range cmd.args {
+		if arg.Lit() == lit {
+			return true
+		}
+	}
+	return false
+}
+
+// rewriteAptKey converts apt-key subcommands that do not take their key
+// from a download in the same pipeline.
+func (r *rewriter) rewriteAptKey(cmd command) {
+	var sub string
+	var operands []*syntax.Word
+	keyserver := "keyserver.ubuntu.com"
+	var keyIDs []string
+	for i := 0; i < len(cmd.args); i++ {
+		arg := cmd.args[i]
+		lit := arg.Lit()
+		switch {
+		case lit == "--keyserver" && i+1 < len(cmd.args):
+			i++
+			keyserver = keyserverHost(cmd.args[i].Lit())
+		case lit == "--recv-keys" || lit == "--recv":
+			for _, id := range cmd.args[i+1:] {
+				keyIDs = append(keyIDs, id.Lit())
+			}
+			i = len(cmd.args)
+		case strings.HasPrefix(lit, "-") && lit != "-":
+		case sub == "":
+			sub = lit
+		default:
+			operands = append(operands, arg)
+		}
+	}
+
+	switch {
+	case sub == "add" && len(operands) == 1:
+		file := r.text(operands[0])
+		if file == "-" {
+			file = "/dev/stdin"
+		}
+		r.replace(cmd.call, r.target.ImportKey(r.sudoText(cmd), file))
+	case sub == "adv" && len(keyIDs) > 0:
+		var imports []string
+		for _, id := range keyIDs {
+			imports = append(imports, r.target.ImportKey(r.sudoText(cmd), shellQuote(keyserverURL(keyserver, id))))
+		}
+		r.replace(cmd.call, strings.Join(imports, " && "))
+	case sub == "list" || sub == "finger" || sub == "fingerprint":
+		r.replace(cmd.call, r.target.ListKeys())
+	case sub == "del" && len(operands) == 1:
+		r.replace(cmd.call, r.target.DeleteKey(r.sudoText(cmd), operands[0].Lit()))
+	default:
+		r.stub(cmd, fmt.Sprintf("cannot convert apt-key %s", r.wordsText(cmd.args)))
+	}
+}
+
+// keyserverHost extracts the host from a keyserver given as a URL such as
+// hkp://keyserver.ubuntu.com:80.
+func keyserverHost(keyserver string) string {
+	if u, err := url.Parse(keyserver); err == nil && u.Hostname() != "" {
+		return u.Hostname()
+	}
+	return strings.Split(keyserver, ":")[0]
+}
+
+func keyserverURL(host, id string) string {
+	return fmt.Sprintf("https://%s/pks/lookup?op=get&search=0x%s", host, strings.TrimPrefix(id, "0x"))
+}
@@ -0,0 +1,195 @@
+package converter
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// PackageKind tells how a script installs a package.
+type PackageKind string
+
+const (
+	AptPackage  PackageKind = "apt"  // installed with apt or apt-get
+	SnapPackage PackageKind = "snap" // installed with snap
+	DebDownload PackageKind = "deb"  // a downloaded .deb file
+)
+
+// ScriptPackage is a package installed by a script. Name is the Debian
+// package or snap name, or the URL of a downloaded .deb.
+type ScriptPackage struct {
+	Name string
+	Kind PackageKind
+}
+
+// NixModule selects the option a generated Nix module lists its packages
+// under.
+type NixModule int
+
+const (
+	HomeManagerModule NixModule = iota // home.packages
+	NixOSModule                        // environment.systemPackages
+)
+
+func (m NixModule) option() string {
+	if m == NixOSModule {
+		return "environment.systemPackages"
+	}
+	return "home.packages"
+}
+
+// ExtractPackages returns the packages the script at filePath installs
+// with apt, snap or a downloaded .deb, in the order it installs them.
+// Version pins of apt packages are dropped, as nixpkgs has one version.
+func ExtractPackages(filePath string) ([]ScriptPackage, error) {
+	src, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+	file, err := parseScript(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+
+	r := &rewriter{src: src}
+	var pkgs []ScriptPackage
+	add := func(name string, kind PackageKind) {
+		pkg := ScriptPackage{Name: name, Kind: kind}
+		for _, existing := range pkgs {
+			if existing == pkg {
+				return
+			}
+		}
+		pkgs = append(pkgs, pkg)
+	}
+
+	syntax.Walk(file, func(node syntax.Node) bool {
+		call, ok := node.(*syntax.CallExpr)
+		if !ok {
+			return true
+		}
+		cmd, ok := splitCommand(call)
+		if !ok {
+			return true
+		}
+		switch cmd.name.Lit() {
+		case "apt", "apt-get":
+			inv := parseApt(cmd)
+			if inv.sub() != "install" {
+				break
+			}
+			// .deb files are recorded by their download.
+			for _, operand := range inv.operands {
+				name, _, _ := strings.Cut(operand.Lit(), "=")
+				if name != "" && !strings.ContainsAny(name, "/*") {
+					add(name, AptPackage)
+				}
+			}
+		case "snap":
+			if len(cmd.args) == 0 || cmd.args[0].Lit() != "install" {
+				break
+			}
+			for _, arg := range cmd.args[1:] {
+				if name := arg.Lit(); name != "" && !strings.HasPrefix(name, "-") {
+					add(name, SnapPackage)
+				}
+			}
+		case "curl", "wget":
+			urlWord, output, ok := r.downloadURL(cmd)
+			if !ok {
+				break
+			}
+			rawURL, _ := r.template(urlWord)
+			if _, known := lookupDeb(r.text(urlWord)); known || strings.HasSuffix(urlBase(rawURL), ".deb") || strings.HasSuffix(output, ".deb") {
+				add(rawURL, DebDownload)
+			}
+		}
+		return true
+	})
+	return pkgs, nil
+}
+
+// NixAttributes returns the nixpkgs attributes of a package, looked up
+// under the "nixpkgs" key of the data file for its kind. An empty list
+// means Nix needs no package for it; ok is false when no attribute is
+// known.
+func NixAttributes(pkg ScriptPackage) (attrs []string, ok bool) {
+	var entry map[string]string
+	switch pkg.Kind {
+	case AptPackage:
+		entry = packages.Packages[pkg.Name]
+	case SnapPackage:
+		entry = snaps.Snaps[pkg.Name]
+	case DebDownload:
+		entry, _ = lookupDeb(pkg.Name)
+	}
+	value, ok := entry["nixpkgs"]
+	return strings.Fields(value), ok
+}
+
+// GenerateNixModule extracts the packages installed by the scripts of
+// apps and returns a Nix module installing them from nixpkgs, for
+// home-manager or NixOS. Packages no nixpkgs attribute is known for, and
+// apps whose scripts install nothing the extraction recognizes, are
+// listed in a commented TODO section, as are apps whose scripts do not
+// parse.
+func GenerateNixModule(apps []AppScript, module NixModule) (string, error) {
+	var names, lines, todo []string
+	seen := make(map[string]bool)
+	for _, app := range apps {
+		names = append(names, app.Name)
+		pkgs, err := ExtractPackages(app.FilePath)
+		if line := errorLine(err); line > 0 {
+			todo = append(todo, fmt.Sprintf("%s: %s does not parse at line %d", app.Name, app.FilePath, line))
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if len(pkgs) == 0 {
+			todo = append(todo, fmt.Sprintf("%s: no apt, snap or .deb packages found in %s", app.Name, app.FilePath))
+			continue
+		}
+
+		var attrs []string
+		for _, pkg := range pkgs {
+			mapped, ok := NixAttributes(pkg)
+			if !ok {
+				todo = append(todo, fmt.Sprintf("%s: %s %s", app.Name, pkg.Kind, pkg.Name))
+				continue
+			}
+			for _, attr := range mapped {
+				if !seen[attr] {
+					seen[attr] = true
+					attrs = append(attrs, attr)
+				}
+			}
+		}
+		if len(attrs) > 0 {
+			lines = append(lines, "    # "+app.Name)
+			for _, attr := range attrs {
+				lines = append(lines, "    "+attr)
+			}
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Generated by ubuntu-to-fedora %s from the omakub scripts of:\n", Version)
+	fmt.Fprintf(&sb, "# %s\n", strings.Join(names, ", "))
+	sb.WriteString("{ pkgs, ... }:\n\n{\n")
+	fmt.Fprintf(&sb, "  %s = with pkgs; [\n", module.option())
+	for _, line := range lines {
+		sb.WriteString(line + "\n")
+	}
+	sb.WriteString("  ];\n")
+	if len(todo) > 0 {
+		sb.WriteString("\n  # TODO: no nixpkgs attribute is known for these; add them by hand:\n")
+		for _, item := range todo {
+			sb.WriteString("  #   " + item + "\n")
+		}
+	}
+	sb.WriteString("}\n")
+	return sb.String(), nil
+}
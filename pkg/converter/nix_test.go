@@ -0,0 +1,108 @@
+package converter_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ubuntu-to-fedora/pkg/converter"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeApp writes an app script to dir and returns it as a TUI choice
+func writeApp(t *testing.T, dir, name, script string) converter.AppScript {
+	t.Helper()
+	path := filepath.Join(dir, name+".sh")
+	if err := os.WriteFile(path, []byte(script), 0644); err != nil {
+		t.Fatalf("Failed to write test script: %v", err)
+	}
+	return converter.AppScript{Name: name, FilePath: path}
+}
+
+// TestExtractPackages tests the discovery of the packages a script installs
+func TestExtractPackages(t *testing.T) {
+	app := writeApp(t, t.TempDir(), "Tools", `#!/bin/bash
+sudo apt update
+sudo apt install -y curl git
+sudo apt-get remove -y nano
+sudo snap install --classic code
+cd /tmp
+wget -O chrome.deb https://dl.google.com/linux/direct/google-chrome-stable_current_amd64.deb
+sudo apt install -y ./chrome.deb git
+sudo apt install -y nginx=1.24.0-2ubuntu7
+`)
+
+	pkgs, err := converter.ExtractPackages(app.FilePath)
+	assert.NoError(t, err, "Expected no error during extraction")
+	assert.Equal(t, []converter.ScriptPackage{
+		{Name: "curl", Kind: converter.AptPackage},
+		{Name: "git", Kind: converter.AptPackage},
+		{Name: "code", Kind: converter.SnapPackage},
+		{Name: "https://dl.google.com/linux/direct/google-chrome-stable_current_amd64.deb", Kind: converter.DebDownload},
+		{Name: "nginx", Kind: converter.AptPackage},
+	}, pkgs, "Installed packages should be listed once, in order")
+}
+
+// TestNixAttributes tests the nixpkgs attributes of the catalog entries
+func TestNixAttributes(t *testing.T) {
+	tests := []struct {
+		pkg      converter.ScriptPackage
+		expected []string
+		ok       bool
+	}{
+		{pkg: converter.ScriptPackage{Name: "fd-find", Kind: converter.AptPackage}, expected: []string{"fd"}, ok: true},
+		{pkg: converter.ScriptPackage{Name: "build-essential", Kind: converter.AptPackage}, expected: []string{"gcc", "gnumake"}, ok: true},
+		{pkg: converter.ScriptPackage{Name: "software-properties-common", Kind: converter.AptPackage}, expected: []string{}, ok: true},
+		{pkg: converter.ScriptPackage{Name: "libssl-dev", Kind: converter.AptPackage}, ok: false},
+		{pkg: converter.ScriptPackage{Name: "zoom-client", Kind: converter.SnapPackage}, expected: []string{"zoom-us"}, ok: true},
+		{pkg: converter.ScriptPackage{Name: "https://zoom.us/client/latest/zoom_amd64.deb", Kind: converter.DebDownload}, expected: []string{"zoom-us"}, ok: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pkg.Name, func(t *testing.T) {
+			attrs, ok := converter.NixAttributes(tt.pkg)
+			assert.Equal(t, tt.ok, ok, "The mapping should be known or not")
+			if tt.ok {
+				assert.ElementsMatch(t, tt.expected, attrs, "The nixpkgs attributes should match")
+			}
+		})
+	}
+}
+
+// TestGenerateNixModule tests the generated module and its TODO section
+func TestGenerateNixModule(t *testing.T) {
+	dir := t.TempDir()
+	apps := []converter.AppScript{
+		writeApp(t, dir, "Terminal", "sudo apt install -y fd-find ripgrep libssl-dev software-properties-common\n"),
+		writeApp(t, dir, "Search", "sudo apt install -y ripgrep fzf\n"),
+		writeApp(t, dir, "Font", "curl -fsSL https://example.com/font.zip -o font.zip\n"),
+		writeApp(t, dir, "Broken", "if true; then\n"),
+	}
+
+	module, err := converter.GenerateNixModule(apps, converter.HomeManagerModule)
+	assert.NoError(t, err, "Expected no error during generation")
+	assert.Equal(t, `# Generated by ubuntu-to-fedora `+converter.Version+` from the omakub scripts of:
+# Terminal, Search, Font, Broken
+{ pkgs, ... }:
+
+{
+  home.packages = with pkgs; [
+    # Terminal
+    fd
+    ripgrep
+    # Search
+    fzf
+  ];
+
+  # TODO: no nixpkgs attribute is known for these; add them by hand:
+  #   Terminal: apt libssl-dev
+  #   Font: no apt, snap or .deb packages found in `+apps[2].FilePath+`
+  #   Broken: `+apps[3].FilePath+` does not parse at line 1
+}
+`, module, "The module should list the mapped packages per app")
+
+	module, err = converter.GenerateNixModule(apps[:1], converter.NixOSModule)
+	assert.NoError(t, err, "Expected no error during generation")
+	assert.Contains(t, module, "  environment.systemPackages = with pkgs; [\n", "NixOS modules should use the system packages")
+}
@@ -0,0 +1,215 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OpenSUSE converts scripts for openSUSE Tumbleweed, which installs
+// packages with zypper. PPAs map to projects on the openSUSE Build Service.
+var OpenSUSE Target = openSUSE{}
+
+type openSUSE struct{}
+
+// obsRepositories is the URL of the Tumbleweed builds of a Build Service
+// project, with the colons of the project name followed by slashes.
+const obsRepositories = "https://download.opensuse.org/repositories/%s/openSUSE_Tumbleweed/"
+
+// zypperSubcommands maps apt and apt-get subcommands to zypper's.
+// Tumbleweed is a rolling release and is only upgraded with dist-upgrade.
+// Refreshing imports the signing keys of repositories added by the script.
+var zypperSubcommands = map[string]string{
+	"install":      "install",
+	"reinstall":    "install --force",
+	"remove":       "remove",
+	"purge":        "remove",
+	"autoremove":   "",
+	"update":       "--gpg-auto-import-keys refresh",
+	"upgrade":      "dist-upgrade",
+	"dist-upgrade": "dist-upgrade",
+	"full-upgrade": "dist-upgrade",
+	"search":       "search",
+	"show":         "info",
+	"list":         "search",
+	"depends":      "info --requires",
+	"rdepends":     "search --requires",
+	"clean":        "clean",
+	"autoclean":    "clean",
+	"download":     "download",
+}
+
+// zypperCacheSubcommands maps apt-cache subcommands to zypper. policy
+// without packages, which lists the repositories, is looked up as
+// policy-repos.
+var zypperCacheSubcommands = map[string]string{
+	"search":       "search",
+	"show":         "info",
+	"showpkg":      "info",
+	"madison":      "search --details --match-exact",
+	"depends":      "info --requires",
+	"rdepends":     "search --requires",
+	"policy":       "search --details --match-exact",
+	"policy-repos": "repos",
+}
+
+// zypperOptions maps apt options to zypper's. An empty value means zypper
+// has no counterpart and the option is dropped with a warning.
+var zypperOptions = map[string]string{
+	"-y":                           "--non-interactive",
+	"--yes":                        "--non-interactive",
+	"--assume-yes":                 "--non-interactive",
+	"-q":                           "--quiet",
+	"-qq":                          "--quiet",
+	"--quiet":                      "--quiet",
+	"--no-install-recommends":      "--no-recommends",
+	"--install-recommends":         "--recommends",
+	"--allow-unauthenticated":      "--no-gpg-checks",
+	"-d":                           "--download-only",
+	"--download-only":              "--download-only",
+	"-s":                           "--dry-run",
+	"--simulate":                   "--dry-run",
+	"--dry-run":                    "--dry-run",
+	"--installed":                  "--installed-only",
+	"-a":                           "--details",
+	"--all-versions":               "--details",
+	"--allow-downgrades":           "--oldpackage",
+	"--reinstall":                  "--force",
+	"--upgradable":                 "",
+	"-m":                           "",
+	"--fix-missing":                "",
+	"-f":                           "",
+	"--fix-broken":                 "",
+	"--allow-remove-essential":     "",
+	"--allow-change-held-packages": "",
+	"--no-install-suggests":        "",
+	"--no-upgrade":                 "",
+	"--only-upgrade":               "",
+	"--purge":                      "",
+	"-o":                           "",
+	"--option":                     "",
+	"-t":                           "",
+	"--target-release":             "",
+	"-c":                           "",
+	"--config-file":                "",
+}
+
+// zypperGlobalOptions are the options zypper only accepts before the
+// subcommand.
+var zypperGlobalOptions = map[string]bool{
+	"--non-interactive": true,
+	"--quiet":           true,
+	"--no-gpg-checks":   true,
+}
+
+func (openSUSE) ID() string             { return "opensuse-tumbleweed" }
+func (openSUSE) Name() string           { return "openSUSE Tumbleweed" }
+func (openSUSE) PackageManager() string { return "zypper" }
+func (openSUSE) rpm()                   {}
+
+func (openSUSE) Subcommand(tool, sub string) (string, bool) {
+	if tool == "apt-cache" {
+		op, ok := zypperCacheSubcommands[sub]
+		return op, ok
+	}
+	op, ok := zypperSubcommands[sub]
+	return op, ok
+}
+
+func (openSUSE) Option(flag string) (string, bool) {
+	op, ok := zypperOptions[flag]
+	return op, ok
+}
+
+func (openSUSE) GlobalOption(spelling string) bool {
+	return zypperGlobalOptions[spelling]
+}
+
+func (openSUSE) Install(sudo string, pkgs []string) string {
+	return sudo + "zypper --non-interactive install " + strings.Join(pkgs, " ")
+}
+
+func (openSUSE) Remove(sudo string, pkgs []string) string {
+	return sudo + "zypper --non-interactive remove " + strings.Join(pkgs, " ")
+}
+
+// GroupInstall installs patterns, openSUSE's package groups.
+func (openSUSE) GroupInstall(sudo string, ids []string) string {
+	return sudo + "zypper --non-interactive install -t pattern " + strings.Join(ids, " ")
+}
+
+// Pin uses zypper's name=version form. zypper cannot match wildcard
+// versions.
+func (openSUSE) Pin(name, version string) (string, bool) {
+	if strings.Contains(version, "*") {
+		return "", false
+	}
+	return name + "=" + version, true
+}
+
+// ExtraRepos always fails: Tumbleweed needs no optional repositories for
+// the catalogs' packages.
+func (openSUSE) ExtraRepos(sudo string, names []string) (string, bool) {
+	return "", false
+}
+
+// EnableRepo adds a Build Service project, aliased by its name with
+// underscores for colons, and imports its signing key.
+func (openSUSE) EnableRepo(sudo, id string, remove bool) (string, bool) {
+	alias := strings.ReplaceAll(id, ":", "_")
+	if remove {
+		return sudo + "zypper removerepo " + alias, true
+	}
+	url := fmt.Sprintf(obsRepositories, strings.ReplaceAll(id, ":", ":/"))
+	return sudo + "zypper addrepo --refresh " + url + " " + alias +
+		" && " + sudo + "zypper --gpg-auto-import-keys refresh " + alias, true
+}
+
+func (openSUSE) AddRepoURL(sudo, url string) (string, bool) {
+	return sudo + "zypper addrepo --refresh " + url + " && " + sudo + "zypper --gpg-auto-import-keys refresh", true
+}
+
+// RepoFile writes a zypper repository file. Its signing key is imported
+// when the repositories are refreshed.
+func (openSUSE) RepoFile(id string, repos []Repo) (string, string, bool) {
+	sections := make([]string, len(repos))
+	for i, repo := range repos {
+		lines := []string{
+			"[" + repo.ID + "]",
+			"name=" + repo.Name,
+			"baseurl=" + repo.BaseURL,
+			"enabled=1",
+			"autorefresh=1",
+			"type=rpm-md",
+			"gpgcheck=1",
+		}
+		if repo.GPGKey != "" {
+			lines = append(lines, "gpgkey="+repo.GPGKey)
+		}
+		sections[i] = strings.Join(lines, "\n")
+	}
+	return fmt.Sprintf("/etc/zypp/repos.d/%s.repo", id), strings.Join(sections, "\n\n"), true
+}
+
+// ImportKey, ListKeys and DeleteKey use rpm, like on Fedora.
+func (openSUSE) ImportKey(sudo, source string) string {
+	return Fedora.ImportKey(sudo, source)
+}
+
+func (openSUSE) ListKeys() string {
+	return Fedora.ListKeys()
+}
+
+func (openSUSE) DeleteKey(sudo, id string) string {
+	return Fedora.DeleteKey(sudo, id)
+}
+
+func (openSUSE) Arch(debian string) (string, bool) {
+	arch, ok := rpmArches[debian]
+	return arch, ok
+}
+
+// DevPackage keeps the lib prefix, as openSUSE names development packages
+// after the library: libfoo-dev becomes libfoo-devel.
+func (openSUSE) DevPackage(name string) (string, bool) {
+	return strings.TrimSuffix(name, "-dev") + "-devel", true
+}
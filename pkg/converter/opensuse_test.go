@@ -0,0 +1,61 @@
+package converter_test
+
+import (
+	"testing"
+
+	"ubuntu-to-fedora/pkg/converter"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestOpenSUSETarget tests the conversion of scripts for openSUSE Tumbleweed
+func TestOpenSUSETarget(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		warning  string
+	}{
+		{
+			name:     "Package names",
+			input:    "sudo apt install -y fd-find python3-pip",
+			expected: "sudo zypper --non-interactive install fd python3-pip",
+		},
+		{
+			name:  "PPA from the Build Service",
+			input: "sudo add-apt-repository -y ppa:git-core/ppa",
+			expected: "sudo zypper addrepo --refresh https://download.opensuse.org/repositories/devel:/tools:/scm/openSUSE_Tumbleweed/ devel_tools_scm" +
+				" && sudo zypper --gpg-auto-import-keys refresh devel_tools_scm",
+		},
+		{
+			name:     "Removed PPA",
+			input:    "sudo add-apt-repository -y -r ppa:longsleep/golang-backports",
+			expected: "sudo zypper removerepo devel_languages_go",
+		},
+		{
+			name:     "PPA in the openSUSE repositories",
+			input:    "sudo add-apt-repository -y ppa:zhangsongcui3371/fastfetch",
+			expected: "true",
+			warning:  "removed ppa:zhangsongcui3371/fastfetch: its packages are in the openSUSE Tumbleweed repositories",
+		},
+		{
+			name:     "Quoted os-release ID",
+			input:    `[ "$(lsb_release -is)" = Ubuntu ] && echo ok`,
+			expected: `[ "$(sed -n 's/^ID=//p' /etc/os-release | tr -d '"')" = opensuse-tumbleweed ] && echo ok`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converted, report := convertScriptFor(t, tt.input, converter.OpenSUSE)
+			assert.Equal(t, tt.expected, converted, "Commands should be converted for openSUSE")
+			if tt.warning != "" {
+				var messages []string
+				for _, d := range report.Diagnostics {
+					messages = append(messages, d.Message)
+				}
+				assert.Contains(t, messages, tt.warning, "The conversion should be reported")
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package converter
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+//go:embed data/packages.json
+var packagesJSON []byte
+
+// packageMap is the embedded Debian package name table. Each entry maps a
+// target distro to a space-separated list of package names; an empty list
+// means the package is not needed on that distro. For Arch Linux, names
+// prefixed with aur/ are built from the Arch User Repository.
+// "<distro>_repos" lists the optional repositories, such as EPEL, that
+// provide the packages. Conversions from Fedora read the table backwards;
+// "fedora_alias" marks names, such as transitional packages, that share
+// their Fedora name with another entry and are not converted back to.
+// "nixpkgs" holds the attributes of generated Nix modules. The version is
+// bumped whenever entries are renamed or removed.
+type packageMap struct {
+	Version  string                       `json:"version"`
+	Packages map[string]map[string]string `json:"packages"`
+}
+
+var packages packageMap
+
+func init() {
+	mustUnmarshal("packages.json", packagesJSON, &packages)
+}
+
+// PackageMapVersion returns the version of the embedded package name table.
+func PackageMapVersion() string {
+	return packages.Version
+}
+
+// mustUnmarshal decodes one of the embedded data files. They are part of
+// the binary, so a decoding error is a build defect.
+func mustUnmarshal(name string, data []byte, v interface{}) {
+	if err := json.Unmarshal(data, v); err != nil {
+		panic(fmt.Sprintf("invalid embedded %s: %v", name, err))
+	}
+}
+
+// translatePackage returns the target's package names for a Debian
+// package. The table is consulted first, then the target's -dev naming
+// heuristics, whose guesses are reported. ok is false when no mapping is
+// known or the target's release does not provide the package.
+func (r *rewriter) translatePackage(name string) (names []string, ok bool) {
+	if entry, found := packages.Packages[name]; found {
+		if mapped, found := catalogValue(r.target, entry, ""); found {
+			if !releaseProvides(r.target, entry) {
+				return nil, false
+			}
+			return strings.Fields(mapped), true
+		}
+	}
+
+	if strings.HasSuffix(name, "-dev") {
+		if devel, ok := r.target.DevPackage(name); ok {
+			r.guessed(name, packageName(devel))
+			return []string{devel}, true
+		}
+	}
+
+	return nil, false
+}
+
+// packageProvided reports whether the target's release provides a Debian
+// package, as far as the table knows.
+func packageProvided(t Target, name string) bool {
+	entry, found := packages.Packages[name]
+	return !found || releaseProvides(t, entry)
+}
+
+// packageRepos returns the optional repositories providing the target's
+// packages for a Debian package.
+func (r *rewriter) packageRepos(name string) []string {
+	repos, _ := catalogValue(r.target, packages.Packages[name], "_repos")
+	return strings.Fields(repos)
+}
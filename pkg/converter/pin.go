@@ -0,0 +1,60 @@
+package converter
+
+import (
+	"regexp"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// debianRepackPattern matches Debian repack markers in upstream versions,
+// such as 1.2.3+dfsg1 or 4.5+ds.
+var debianRepackPattern = regexp.MustCompile(`\+(?:dfsg|ds)[0-9.]*$`)
+
+// rpmVersion returns the upstream part of a Debian version: the epoch, the
+// Debian revision and repack markers are removed. Wildcards are kept.
+func rpmVersion(version string) string {
+	if i := strings.Index(version, ":"); i >= 0 {
+		version = version[i+1:]
+	}
+	if i := strings.LastIndex(version, "-"); i > 0 && !strings.HasSuffix(version, "*") {
+		version = version[:i]
+	}
+	return debianRepackPattern.ReplaceAllString(version, "")
+}
+
+// translatePin rewrites a pinned package argument, pkg=version, to the
+// target's form, pkg-version for dnf. It reports whether operand was a
+// pin, and whether it should be dropped because the target does not need
+// the package.
+func (r *rewriter) translatePin(operand *syntax.Word) (pin, drop bool) {
+	value, dynamic := r.template(operand)
+	i := strings.Index(value, "=")
+	if dynamic || i <= 0 {
+		return false, false
+	}
+	name, version := value[:i], value[i+1:]
+
+	names, ok := r.translatePackage(name)
+	switch {
+	case !ok:
+		r.unmapped(name)
+		names = []string{name}
+	case len(names) == 0:
+		return true, true
+	case len(names) > 1:
+		r.replace(operand, strings.Join(names, " "))
+		r.warn(operand, "dropped the version pin of %s: it maps to several %s packages", value, r.target.Name())
+		return true, false
+	}
+
+	pinned, ok := r.target.Pin(names[0], rpmVersion(version))
+	if !ok {
+		r.replace(operand, shellQuote(packageName(names[0])))
+		r.warn(operand, "dropped the version pin of %s: %s cannot install a given version", value, r.target.PackageManager())
+		return true, false
+	}
+	r.replace(operand, shellQuote(pinned))
+	r.warn(operand, "pinned %s as %s: %s builds differ from Ubuntu's, check that this version exists", value, pinned, r.target.Name())
+	return true, false
+}
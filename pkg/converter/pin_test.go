@@ -0,0 +1,77 @@
+package converter_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestVersionPins tests the translation of pinned package arguments
+func TestVersionPins(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		warnings int
+	}{
+		{
+			name:     "Debian revision",
+			input:    "sudo apt install -y nginx=1.24.0-2ubuntu7",
+			expected: "sudo dnf install -y nginx-1.24.0",
+			warnings: 1,
+		},
+		{
+			name:     "Epoch and repack marker",
+			input:    "sudo apt-get install -y libssl-dev=1:3.0.2+dfsg1-1ubuntu1 curl",
+			expected: "sudo dnf install -y openssl-devel-3.0.2 curl",
+			warnings: 1,
+		},
+		{
+			name:     "Wildcard pin",
+			input:    "sudo apt install -y docker-ce=5:27.* docker-ce-cli='5:27.1.1-1~ubuntu.24.04~noble'",
+			expected: "sudo dnf install -y 'docker-ce-27.*' docker-ce-cli-27.1.1",
+			warnings: 2,
+		},
+		{
+			name:     "Pin of a meta-package",
+			input:    "sudo apt install -y build-essential=12.9ubuntu3 curl",
+			expected: "sudo dnf group install -y development-tools c-development && sudo dnf install -y curl",
+		},
+		{
+			name:     "Pin of a package Fedora does not need",
+			input:    "sudo apt install -y git apt-transport-https=2.4.5",
+			expected: "sudo dnf install -y git",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converted, report := convertScript(t, tt.input)
+			assert.Equal(t, tt.expected, converted, "Pins should use the dnf name-version form")
+
+			warnings := 0
+			for _, d := range report.Diagnostics {
+				if strings.HasPrefix(d.Message, "pinned ") {
+					warnings++
+				}
+			}
+			assert.Equal(t, tt.warnings, warnings, "Each pin should be reported")
+		})
+	}
+}
+
+// TestMetaPackagePins tests that pinned meta-packages still become package
+// groups, with the pin reported as dropped
+func TestMetaPackagePins(t *testing.T) {
+	converted, report := convertScript(t, "sudo apt install -y ubuntu-restricted-extras=72")
+	assert.Equal(t, "sudo dnf install -y https://mirrors.rpmfusion.org/free/fedora/rpmfusion-free-release-$(rpm -E %fedora).noarch.rpm"+
+		" https://mirrors.rpmfusion.org/nonfree/fedora/rpmfusion-nonfree-release-$(rpm -E %fedora).noarch.rpm && sudo dnf group install -y multimedia",
+		converted, "The meta-package should become a group needing RPM Fusion")
+
+	var messages []string
+	for _, d := range report.Diagnostics {
+		messages = append(messages, d.Message)
+	}
+	assert.Contains(t, messages, "dropped the version pin of ubuntu-restricted-extras=72: Fedora installs it as a package group", "The dropped pin should be reported")
+}
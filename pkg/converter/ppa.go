@@ -0,0 +1,104 @@
+package converter
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+//go:embed data/ppas.json
+var ppasJSON []byte
+
+// ppaCatalog maps Launchpad PPAs ("owner/name") to target distro
+// repositories. For Fedora the value is a COPR project, for openSUSE a
+// Build Service project such as the devel project of a package; an empty value
+// means the software is already in the distro's repositories, or for Arch
+// Linux in the AUR. Conversions from Fedora map COPR projects back to the
+// PPA.
+type ppaCatalog struct {
+	PPAs map[string]map[string]string `json:"ppas"`
+}
+
+var ppas ppaCatalog
+
+func init() {
+	mustUnmarshal("ppas.json", ppasJSON, &ppas)
+}
+
+// ubuntuComponents are archive components add-apt-repository can enable.
+// The targets have no equivalent switch, the packages are in their
+// repositories.
+var ubuntuComponents = map[string]bool{
+	"main":       true,
+	"universe":   true,
+	"multiverse": true,
+	"restricted": true,
+}
+
+// rewriteAddAptRepository converts add-apt-repository. PPAs become the
+// target's repositories, such as COPR projects, when the catalog knows
+// one, and a failing stub otherwise.
+func (r *rewriter) rewriteAddAptRepository(cmd command) {
+	remove := false
+	var operands []*syntax.Word
+	for _, arg := range cmd.args {
+		lit := arg.Lit()
+		switch {
+		case lit == "-r" || lit == "--remove":
+			remove = true
+		case strings.HasPrefix(lit, "-"):
+		default:
+			operands = append(operands, arg)
+		}
+	}
+	if len(operands) != 1 {
+		r.stub(cmd, fmt.Sprintf("cannot convert add-apt-repository %s", r.wordsText(operands)))
+		return
+	}
+
+	repo, ok := literal(operands[0])
+	switch {
+	case !ok:
+		r.addRepoURL(cmd, r.text(operands[0]))
+	case strings.HasPrefix(repo, "ppa:"):
+		r.convertPPA(cmd, strings.TrimPrefix(repo, "ppa:"), remove)
+	case strings.HasPrefix(repo, "deb "):
+		r.stub(cmd, "cannot convert apt source line; add a .repo file for it manually")
+	case ubuntuComponents[repo]:
+		r.replace(cmd.call, "true")
+		r.warn(cmd.call, "removed add-apt-repository %s: %s has no archive components", repo, r.target.Name())
+	default:
+		r.addRepoURL(cmd, shellQuote(repo))
+	}
+}
+
+func (r *rewriter) addRepoURL(cmd command, url string) {
+	text, ok := r.target.AddRepoURL(r.sudoText(cmd), url)
+	if !ok {
+		r.stub(cmd, fmt.Sprintf("cannot add the repository %s on %s; install its packages manually", url, r.target.Name()))
+		return
+	}
+	r.replace(cmd.call, text)
+}
+
+func (r *rewriter) convertPPA(cmd command, ppa string, remove bool) {
+	id, ok := catalogValue(r.target, ppas.PPAs[ppa], "")
+	switch {
+	case !ok && r.dnf():
+		r.stub(cmd, fmt.Sprintf("no COPR equivalent known for ppa:%s; enable a repository for it on %s manually", ppa, r.target.Name()))
+	case !ok:
+		r.stub(cmd, fmt.Sprintf("no %s repository known for ppa:%s; install its packages manually", r.target.Name(), ppa))
+	case id == "":
+		r.replace(cmd.call, "true")
+		r.warn(cmd.call, "removed ppa:%s: its packages are in the %s repositories", ppa, r.target.Name())
+	default:
+		text, ok := r.target.EnableRepo(r.sudoText(cmd), id, remove)
+		if !ok {
+			r.stub(cmd, fmt.Sprintf("cannot enable ppa:%s on %s", ppa, r.target.Name()))
+			return
+		}
+		r.replace(cmd.call, text)
+	}
+}
@@ -0,0 +1,279 @@
+package converter
+
+import (
+	"regexp"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// Kinds of OS release information a shell word can hold.
+const (
+	releaseID       = "id"
+	releaseCodename = "codename"
+	releaseVersion  = "version"
+)
+
+// releaseParams maps /etc/os-release and /etc/lsb-release variables to the
+// information they hold.
+var releaseParams = map[string]string{
+	"ID":               releaseID,
+	"ID_LIKE":          releaseID,
+	"DISTRIB_ID":       releaseID,
+	"VERSION_CODENAME": releaseCodename,
+	"UBUNTU_CODENAME":  releaseCodename,
+	"DISTRIB_CODENAME": releaseCodename,
+	"VERSION_ID":       releaseVersion,
+	"DISTRIB_RELEASE":  releaseVersion,
+}
+
+var (
+	distroPattern        = regexp.MustCompile(`(?i)\b(ubuntu|debian|linuxmint|pop)\b`)
+	ubuntuVersionPattern = regexp.MustCompile(`^[0-9]{2}\.[0-9]{2}`)
+
+	// codenameExpansion matches the usual ways of expanding the Ubuntu
+	// codename in a URL.
+	codenameExpansion = regexp.MustCompile(`\$\(\s*lsb_release\s+(?:-cs|-sc|--codename\s+--short|--short\s+--codename)\s*\)` +
+		`|\$\(\s*\.\s+/etc/os-release\s*&&\s*echo\s+"?\$\{?(?:VERSION_CODENAME|UBUNTU_CODENAME)\}?"?\s*\)` +
+		`|\$\{?(?:VERSION_CODENAME|UBUNTU_CODENAME)\}?`)
+)
+
+// lsbFields returns the fields an lsb_release command prints and whether
+// it prints them without labels.
+func lsbFields(cmd command) (fields string, short bool) {
+	long := map[string]byte{"--id": 'i', "--description": 'd', "--release": 'r', "--codename": 'c', "--all": 'a'}
+	for _, arg := range cmd.args {
+		lit := arg.Lit()
+		switch {
+		case lit == "--short":
+			short = true
+		case long[lit] != 0:
+			fields += string(long[lit])
+		case strings.HasPrefix(lit, "-") && !strings.HasPrefix(lit, "--"):
+			for _, c := range lit[1:] {
+				if c == 's' {
+					short = true
+				} else {
+					fields += string(c)
+				}
+			}
+		}
+	}
+	return fields, short
+}
+
+// rewriteLsbRelease converts lsb_release, which the targets do not
+// install, to reads of the release number and /etc/os-release.
+func (r *rewriter) rewriteLsbRelease(cmd command) {
+	fields, short := lsbFields(cmd)
+	if (fields == "c" || fields == "r") && !r.require(cmd, r.released()) {
+		return
+	}
+	switch fields {
+	case "c":
+		r.replaceCommand(cmd, "rpm -E "+r.releaseMacro(), false)
+		r.warn(cmd.call, "%s releases have no codenames; lsb_release -cs now prints the release number", r.target.Name())
+	case "r":
+		r.replaceCommand(cmd, "rpm -E "+r.releaseMacro(), false)
+	case "i":
+		r.replaceCommand(cmd, `sed -n 's/^ID=//p' /etc/os-release | tr -d '"'`, false)
+	case "d":
+		r.replaceCommand(cmd, `sed -n 's/^PRETTY_NAME=//p' /etc/os-release | tr -d '"'`, false)
+	default:
+		r.replaceCommand(cmd, "cat /etc/os-release", false)
+		r.warn(cmd.call, "lsb_release %s now prints /etc/os-release, which is formatted differently", r.wordsText(cmd.args))
+		return
+	}
+	if !short {
+		r.warn(cmd.call, "lsb_release without -s labels its output; the replacement prints only the value")
+	}
+}
+
+// rewriteCodenameParam points expansions of the Ubuntu codename at the
+// release number of targets with numbered releases, and flags them for
+// the others.
+func (r *rewriter) rewriteCodenameParam(p *syntax.ParamExp) {
+	if p.Param == nil {
+		return
+	}
+	switch name := p.Param.Value; name {
+	case "VERSION_CODENAME", "UBUNTU_CODENAME":
+		if !r.released() {
+			r.warn(p, "%s has no release codenames; $%s expands to nothing", r.target.Name(), name)
+			return
+		}
+		r.replace(p.Param, "VERSION_ID")
+		r.warn(p, "%s releases have no codenames; $%s now expands to the release number", r.target.Name(), name)
+	case "DISTRIB_CODENAME":
+		r.warn(p, "$DISTRIB_CODENAME comes from /etc/lsb-release, which %s does not have", r.target.Name())
+	}
+}
+
+// releaseKind returns the kind of release information w expands, if any.
+func (r *rewriter) releaseKind(w *syntax.Word) (kind string, fromLsb bool) {
+	syntax.Walk(w, func(node syntax.Node) bool {
+		switch node := node.(type) {
+		case *syntax.ParamExp:
+			if node.Param != nil && releaseParams[node.Param.Value] != "" {
+				kind = releaseParams[node.Param.Value]
+			}
+		case *syntax.CallExpr:
+			cmd, ok := splitCommand(node)
+			if !ok || cmd.name.Lit() != "lsb_release" {
+				break
+			}
+			switch fields, _ := lsbFields(cmd); fields {
+			case "i":
+				kind, fromLsb = releaseID, true
+			case "c":
+				kind, fromLsb = releaseCodename, true
+			case "r":
+				kind, fromLsb = releaseVersion, true
+			}
+		}
+		return kind == ""
+	})
+	return kind, fromLsb
+}
+
+// rewriteReleaseTest converts a comparison of release information with a
+// literal, as found in [ ], test and [[ ]]. Distribution guards now check
+// for the target; codename and Ubuntu version checks are flagged.
+func (r *rewriter) rewriteReleaseTest(x, y *syntax.Word) {
+	kind, fromLsb := r.releaseKind(x)
+	if kind == "" {
+		x, y = y, x
+		if kind, fromLsb = r.releaseKind(x); kind == "" {
+			return
+		}
+	}
+	value, dynamic := r.template(y)
+	if dynamic {
+		return
+	}
+
+	switch kind {
+	case releaseID:
+		if r.rewriteDistroNames(y, fromLsb) {
+			r.renameIDLike(x)
+		}
+	case releaseCodename:
+		r.warn(y, "comparison with the Ubuntu codename %s has no %s counterpart", value, r.target.Name())
+	case releaseVersion:
+		if ubuntuVersionPattern.MatchString(value) {
+			r.warn(y, "comparison with the Ubuntu release %s has no %s counterpart", value, r.target.Name())
+		}
+	}
+}
+
+// rewriteReleaseCase converts case statements over the distribution ID.
+// Patterns of an item that become the same, as in ubuntu|debian, are
+// collapsed into one.
+func (r *rewriter) rewriteReleaseCase(clause *syntax.CaseClause) {
+	kind, fromLsb := r.releaseKind(clause.Word)
+	if kind != releaseID {
+		return
+	}
+	renamed := false
+	for _, item := range clause.Items {
+		// seen maps the patterns of the item to whether they were rewritten.
+		seen := make(map[string]bool)
+		for i, pattern := range item.Patterns {
+			text, changed := r.distroNames(pattern, fromLsb)
+			if rewritten, dup := seen[text]; dup && (changed || rewritten) {
+				r.edits = append(r.edits, edit{
+					start: item.Patterns[i-1].End().Offset(),
+					end:   pattern.End().Offset(),
+				})
+				continue
+			}
+			seen[text] = changed
+			if !changed {
+				continue
+			}
+			r.replaceDistroNames(pattern, text)
+			if !renamed {
+				r.renameIDLike(clause.Word)
+				renamed = true
+			}
+		}
+	}
+}
+
+// rewriteDistroNames replaces Debian family distribution names in a
+// literal word with the target's ID, and reports whether it did.
+func (r *rewriter) rewriteDistroNames(w *syntax.Word, lower bool) bool {
+	text, ok := r.distroNames(w, lower)
+	if ok {
+		r.replaceDistroNames(w, text)
+	}
+	return ok
+}
+
+// distroNames returns the text of a literal word with Debian family
+// distribution names replaced by the target's ID, keeping their
+// capitalization unless the value is compared against the lowercase ID
+// printed by the lsb_release replacement. ok is false when the word names
+// no such distribution.
+func (r *rewriter) distroNames(w *syntax.Word, lower bool) (text string, ok bool) {
+	text = r.text(w)
+	if _, dynamic := r.template(w); dynamic || !distroPattern.MatchString(text) {
+		return text, false
+	}
+	id := r.targetID()
+	return distroPattern.ReplaceAllStringFunc(text, func(name string) string {
+		if !lower && name[0] >= 'A' && name[0] <= 'Z' {
+			return strings.ToUpper(id[:1]) + id[1:]
+		}
+		return id
+	}), true
+}
+
+// replaceDistroNames replaces w with text, the result of distroNames.
+func (r *rewriter) replaceDistroNames(w *syntax.Word, text string) {
+	if t, ok := r.target.(osReleaseTarget); ok {
+		if _, note := t.osRelease(); note != "" {
+			r.warn(w, "distribution check now matches %s: %s", r.targetID(), note)
+		}
+	}
+	r.replace(w, text)
+}
+
+// targetID returns the ID the target's os-release file sets.
+func (r *rewriter) targetID() string {
+	if t, ok := r.target.(osReleaseTarget); ok {
+		id, _ := t.osRelease()
+		return id
+	}
+	return r.target.ID()
+}
+
+// renameIDLike switches checks of $ID_LIKE to $ID, which names the target
+// exactly. Fedora and Arch Linux do not set ID_LIKE.
+func (r *rewriter) renameIDLike(w *syntax.Word) {
+	syntax.Walk(w, func(node syntax.Node) bool {
+		if p, ok := node.(*syntax.ParamExp); ok && p.Param != nil && p.Param.Value == "ID_LIKE" {
+			r.replace(p.Param, "ID")
+		}
+		return true
+	})
+}
+
+// rewriteTestCommand converts comparisons among the arguments of [ or test.
+func (r *rewriter) rewriteTestCommand(cmd command) {
+	for i := 1; i+1 < len(cmd.args); i++ {
+		switch cmd.args[i].Lit() {
+		case "=", "==", "!=":
+			r.rewriteReleaseTest(cmd.args[i-1], cmd.args[i+1])
+		}
+	}
+}
+
+// releaseverURL replaces expansions of the Ubuntu codename in a repository
+// URL with the $releasever variable dnf expands.
+func releaseverURL(u string) (string, bool) {
+	if !codenameExpansion.MatchString(u) {
+		return u, false
+	}
+	return codenameExpansion.ReplaceAllString(u, "$$releasever"), true
+}
@@ -0,0 +1,86 @@
+package converter_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestReleaseDetection tests the translation of OS release and codename checks
+func TestReleaseDetection(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		flagged  []string
+	}{
+		{
+			name:     "Distribution guard",
+			input:    ". /etc/os-release\nif [ \"$ID\" = ubuntu ]; then echo ok; fi",
+			expected: ". /etc/os-release\nif [ \"$ID\" = fedora ]; then echo ok; fi",
+		},
+		{
+			name:     "Guard on ID_LIKE",
+			input:    "[[ $ID_LIKE == *debian* ]] || exit 1",
+			expected: "[[ $ID == *fedora* ]] || exit 1",
+		},
+		{
+			name:     "Guard on lsb_release",
+			input:    "if [ \"$(lsb_release -is)\" != \"Ubuntu\" ]; then exit 1; fi",
+			expected: `if [ "$(sed -n 's/^ID=//p' /etc/os-release | tr -d '"')" != "fedora" ]; then exit 1; fi`,
+		},
+		{
+			name:     "Case over the distribution",
+			input:    "case \"$ID\" in\n  ubuntu) echo ok ;;\n  *) exit 1 ;;\nesac",
+			expected: "case \"$ID\" in\n  fedora) echo ok ;;\n  *) exit 1 ;;\nesac",
+		},
+		{
+			name:     "Case over the distribution family",
+			input:    "case \"$ID\" in\n  ubuntu|debian) echo ok ;;\n  fedora | centos) exit 0 ;;\nesac",
+			expected: "case \"$ID\" in\n  fedora) echo ok ;;\n  fedora | centos) exit 0 ;;\nesac",
+		},
+		{
+			name:     "Codename in a download URL",
+			input:    "curl -fsSL \"https://example.com/dists/$(lsb_release -cs)/tool.rpm\" -o tool.rpm",
+			expected: "curl -fsSL \"https://example.com/dists/$(rpm -E %fedora)/tool.rpm\" -o tool.rpm",
+			flagged:  []string{"no codenames"},
+		},
+		{
+			name:     "Codename from os-release",
+			input:    "CODENAME=$(. /etc/os-release && echo $VERSION_CODENAME)",
+			expected: "CODENAME=$(. /etc/os-release && echo $VERSION_ID)",
+			flagged:  []string{"no codenames"},
+		},
+		{
+			name:     "Codename comparison",
+			input:    "[ \"$(lsb_release -cs)\" = jammy ] && echo old",
+			expected: "[ \"$(rpm -E %fedora)\" = jammy ] && echo old",
+			flagged:  []string{"no codenames", "Ubuntu codename jammy"},
+		},
+		{
+			name:     "Ubuntu version comparison",
+			input:    "[ \"$VERSION_ID\" = \"24.04\" ] || exit 1",
+			expected: "[ \"$VERSION_ID\" = \"24.04\" ] || exit 1",
+			flagged:  []string{"Ubuntu release 24.04"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converted, report := convertScript(t, tt.input)
+			assert.Equal(t, tt.expected, converted, "Release checks should target Fedora")
+
+			var messages []string
+			for _, d := range report.Diagnostics {
+				messages = append(messages, d.Message)
+			}
+			for _, want := range tt.flagged {
+				assert.Contains(t, strings.Join(messages, "\n"), want, "Expected a diagnostic")
+			}
+			if len(tt.flagged) == 0 {
+				assert.Empty(t, messages, "No diagnostics expected")
+			}
+		})
+	}
+}
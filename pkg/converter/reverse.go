@@ -0,0 +1,799 @@
+package converter
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// ubuntuID is the target recorded in the header of scripts converted from
+// Fedora for Ubuntu.
+const ubuntuID = "ubuntu"
+
+// The reverse conversion reads the same data files as the conversions for
+// Fedora, with their Fedora names mapped back to Debian's. Entries marked
+// "fedora_alias" share their Fedora name with another entry, such as a
+// transitional package, and are not chosen.
+var (
+	debianPackages map[string]string // Fedora package → Debian package
+	debianGroups   map[string]string // dnf group ID → Debian meta-package
+	coprPPAs       map[string]string // COPR project → PPA
+)
+
+func init() {
+	debianPackages = invertCatalog("packages.json", packages.Packages, false)
+	debianGroups = invertCatalog("groups.json", groups.Groups, true)
+	coprPPAs = invertCatalog("ppas.json", ppas.PPAs, false)
+}
+
+// invertCatalog maps the Fedora names of a data file back to its Debian
+// names. Entries with several Fedora names are skipped unless split is
+// set, as they stand for none of them alone. Two entries sharing a Fedora
+// name without either being an alias are a defect of the data file.
+func invertCatalog(name string, entries map[string]map[string]string, split bool) map[string]string {
+	inverse := make(map[string]string)
+	for debian, entry := range entries {
+		fields := strings.Fields(entry["fedora"])
+		if entry["fedora_alias"] == "true" || len(fields) == 0 || (len(fields) > 1 && !split) {
+			continue
+		}
+		for _, field := range fields {
+			if other, ok := inverse[field]; ok {
+				panic(fmt.Sprintf("invalid embedded %s: %s and %s both map from %s; mark one as fedora_alias", name, other, debian, field))
+			}
+			inverse[field] = debian
+		}
+	}
+	return inverse
+}
+
+// aptOptions maps dnf options back to apt's by inverting dnfOptions. Of
+// the apt spellings of an option, the one dnf shares is kept, otherwise
+// the longest.
+var aptOptions = invertOptions(dnfOptions)
+
+func invertOptions(options map[string]string) map[string]string {
+	inverse := make(map[string]string)
+	for apt, dnf := range options {
+		if dnf == "" {
+			continue
+		}
+		current, ok := inverse[dnf]
+		switch {
+		case !ok, apt == dnf:
+		case current == dnf, len(apt) < len(current), len(apt) == len(current) && apt > current:
+			continue
+		}
+		inverse[dnf] = apt
+	}
+	return inverse
+}
+
+// dnfOptionSpellings are dnf's long spellings of the options in dnfOptions.
+var dnfOptionSpellings = map[string]string{
+	"--assumeyes": "-y",
+	"--quiet":     "-q",
+}
+
+// dnfValueOptions are the dnf options taking a value, which may be given
+// as the next argument.
+var dnfValueOptions = map[string]bool{
+	"--setopt":        true,
+	"--enablerepo":    true,
+	"--disablerepo":   true,
+	"--repo":          true,
+	"--repoid":        true,
+	"--releasever":    true,
+	"-x":              true,
+	"--exclude":       true,
+	"--installroot":   true,
+	"--forcearch":     true,
+	"-c":              true,
+	"--config":        true,
+	"--from-repofile": true,
+	"--add-repo":      true,
+}
+
+// dnfAptSubcommands maps dnf subcommands to the apt tool and subcommand
+// doing the same.
+var dnfAptSubcommands = map[string]string{
+	"install":      "apt-get install",
+	"reinstall":    "apt-get install --reinstall",
+	"remove":       "apt-get remove",
+	"erase":        "apt-get remove",
+	"autoremove":   "apt-get autoremove",
+	"upgrade":      "apt-get upgrade",
+	"update":       "apt-get upgrade",
+	"distro-sync":  "apt-get dist-upgrade",
+	"check-update": "apt-get update",
+	"makecache":    "apt-get update",
+	"clean":        "apt-get clean",
+	"search":       "apt-cache search",
+	"info":         "apt-cache show",
+	"list":         "apt list",
+	"repolist":     "apt-cache policy",
+	"download":     "apt-get download",
+}
+
+// dnfPackageSubcommands take package names, which are translated.
+// Search terms are kept, and the operands of the other subcommands, such
+// as clean's "all", are dropped.
+var dnfPackageSubcommands = map[string]bool{
+	"install":     true,
+	"reinstall":   true,
+	"remove":      true,
+	"erase":       true,
+	"autoremove":  true,
+	"upgrade":     true,
+	"update":      true,
+	"distro-sync": true,
+	"info":        true,
+	"download":    true,
+	"list":        true,
+}
+
+// dnfUpgrades refresh the repository metadata before upgrading, which apt
+// only does when asked to with apt-get update.
+var dnfUpgrades = map[string]bool{
+	"upgrade":     true,
+	"update":      true,
+	"distro-sync": true,
+}
+
+const (
+	aptKeyringDir = "/etc/apt/keyrings"
+	aptListDir    = "/etc/apt/sources.list.d"
+	yumReposDir   = "/etc/yum.repos.d/"
+)
+
+// ReplaceFedoraWithUbuntu converts the Fedora scripts under dir for Ubuntu
+// and prints a summary of each conversion.
+func ReplaceFedoraWithUbuntu(dir string) error {
+	reports, err := ConvertFedoraDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, report := range reports {
+		printReport(report, "Fedora", "Ubuntu")
+	}
+
+	fmt.Println("Replacement completed successfully.")
+	return nil
+}
+
+// ConvertFedoraDir converts every shell script under dir in place from
+// Fedora for Ubuntu and returns a report for each of them.
+func ConvertFedoraDir(dir string) ([]Report, error) {
+	return convertDir(dir, ubuntuID, convertFedoraScript)
+}
+
+// convertFedoraScript converts a Fedora script for Ubuntu: dnf and yum
+// become apt-get, COPR projects PPAs, .repo files apt sources with a
+// signed-by keyring and rpm queries dpkg's.
+func convertFedoraScript(src []byte) ([]byte, Report, error) {
+	file, err := parseScript(src)
+	if err != nil {
+		return nil, Report{}, err
+	}
+
+	r := &rewriter{src: src}
+	syntax.Walk(file, func(node syntax.Node) bool {
+		switch node := node.(type) {
+		case *syntax.Stmt:
+			r.trackGrouping(node)
+			return !r.rewriteRepoFile(node)
+		case *syntax.BinaryCmd:
+			r.trackNested(node)
+		case *syntax.CallExpr:
+			if cmd, ok := splitCommand(node); ok {
+				r.rewriteFedoraCommand(cmd)
+			}
+		}
+		return true
+	})
+
+	r.finishGrouping()
+	return r.apply(), r.report, nil
+}
+
+func (r *rewriter) rewriteFedoraCommand(cmd command) {
+	switch cmd.name.Lit() {
+	case "dnf", "dnf5", "yum":
+		r.rewriteDnf(cmd)
+	case "yum-config-manager":
+		r.rewriteConfigManager(cmd, parseDnf(cmd.args))
+	case "rpm":
+		r.rewriteRPM(cmd)
+	}
+}
+
+// dnfInvocation is a dnf command line split into its options, with the
+// values of options taking one, and the subcommand with its operands.
+type dnfInvocation struct {
+	options    []string
+	subcommand string
+	operands   []*syntax.Word
+}
+
+func parseDnf(args []*syntax.Word) dnfInvocation {
+	var inv dnfInvocation
+	for i := 0; i < len(args); i++ {
+		lit := args[i].Lit()
+		switch {
+		case strings.HasPrefix(lit, "-"):
+			if dnfValueOptions[lit] && i+1 < len(args) {
+				i++
+				lit += "=" + args[i].Lit()
+			}
+			inv.options = append(inv.options, lit)
+		case inv.subcommand == "" && lit != "":
+			inv.subcommand = lit
+		default:
+			inv.operands = append(inv.operands, args[i])
+		}
+	}
+	return inv
+}
+
+// option returns the value of a dnf option, given as --opt=value.
+func (inv dnfInvocation) option(flag string) (string, bool) {
+	for _, opt := range inv.options {
+		if value := strings.TrimPrefix(opt, flag+"="); value != opt {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// action splits off the first operand, the action of subcommands such as
+// dnf group and dnf copr.
+func (inv dnfInvocation) action() (string, []*syntax.Word) {
+	if len(inv.operands) == 0 {
+		return "", nil
+	}
+	return inv.operands[0].Lit(), inv.operands[1:]
+}
+
+// rewriteDnf converts dnf and yum to apt-get, apt-cache and apt, and the
+// plugins it is commonly run with to their Ubuntu counterparts.
+func (r *rewriter) rewriteDnf(cmd command) {
+	inv := parseDnf(cmd.args)
+	switch inv.subcommand {
+	case "group", "groups":
+		action, operands := inv.action()
+		r.rewriteDnfGroup(cmd, inv, action, operands)
+		return
+	case "groupinstall", "groupremove":
+		r.rewriteDnfGroup(cmd, inv, strings.TrimPrefix(inv.subcommand, "group"), inv.operands)
+		return
+	case "copr":
+		r.rewriteCopr(cmd, inv)
+		return
+	case "config-manager":
+		r.rewriteConfigManager(cmd, inv)
+		return
+	case "versionlock", "mark":
+		r.rewriteDnfMark(cmd, inv)
+		return
+	}
+
+	spelling, ok := dnfAptSubcommands[inv.subcommand]
+	if !ok {
+		r.stubForUbuntu(cmd)
+		return
+	}
+	operands := inv.operands
+	switch {
+	case inv.subcommand == "search":
+	case !dnfPackageSubcommands[inv.subcommand]:
+		operands = nil
+	case dnfUpgrades[inv.subcommand] && len(operands) > 0:
+		spelling = "apt-get install --only-upgrade"
+	}
+
+	var names []string
+	if len(operands) > 0 && inv.subcommand != "search" {
+		var groups []string
+		var ok bool
+		names, groups, ok = r.debianOperands(cmd, operands)
+		if !ok {
+			return
+		}
+		names = append(names, groups...)
+		if len(names) == 0 {
+			r.replace(cmd.call, "true")
+			r.warn(cmd.call, "removed %s: no packages are needed on Ubuntu", r.wordsText(operands))
+			return
+		}
+	} else {
+		for _, operand := range operands {
+			names = append(names, r.text(operand))
+		}
+	}
+
+	text := joinWords(spelling, r.aptOptions(cmd, inv), names)
+	if dnfUpgrades[inv.subcommand] {
+		text = "apt-get update && " + r.prefixText(cmd) + text
+	}
+	r.replaceCommand(cmd, text, false)
+}
+
+// aptOptions returns the apt spellings of the options of a dnf command.
+// Options apt has no counterpart for are dropped with a warning.
+func (r *rewriter) aptOptions(cmd command, inv dnfInvocation) []string {
+	var options []string
+	for _, opt := range inv.options {
+		flag := opt
+		if spelling, ok := dnfOptionSpellings[flag]; ok {
+			flag = spelling
+		}
+		spelling, ok := aptOptions[flag]
+		if !ok {
+			r.warn(cmd.call, "dropped dnf option %s: apt has no equivalent", opt)
+			continue
+		}
+		if !containsString(options, spelling) {
+			options = append(options, spelling)
+		}
+	}
+	return options
+}
+
+// debianOperands translates the package operands of a dnf command. @group
+// operands become meta-packages, returned separately. dnf plugins and RPM
+// Fusion release packages are dropped, as Ubuntu has RPM Fusion's
+// software in multiverse. ok is
+// false when cmd was replaced by a stub, for RPM files and unknown groups.
+func (r *rewriter) debianOperands(cmd command, operands []*syntax.Word) (names, groups []string, ok bool) {
+	for _, operand := range operands {
+		text := r.text(operand)
+		name, isLit := literal(operand)
+		switch {
+		case strings.Contains(text, "rpmfusion"):
+			r.warn(operand, "removed %s: RPM Fusion's software is in Ubuntu's multiverse component", text)
+		case !isLit:
+			names = append(names, text)
+		case strings.HasSuffix(name, ".rpm") || strings.Contains(name, "://"):
+			r.stub(cmd, fmt.Sprintf("cannot install the RPM package %s on Ubuntu; install its .deb instead", name))
+			return nil, nil, false
+		case strings.HasPrefix(name, "@"):
+			meta, found := debianGroups[strings.TrimPrefix(name, "@")]
+			if !found {
+				r.stub(cmd, fmt.Sprintf("no Ubuntu meta-package known for the dnf group %s", strings.TrimPrefix(name, "@")))
+				return nil, nil, false
+			}
+			if !containsString(groups, meta) {
+				groups = append(groups, meta)
+			}
+		case strings.HasPrefix(name, "dnf-command(") || strings.Contains(name, "dnf-plugin-"):
+			r.warn(operand, "removed %s: apt needs no dnf plugins", name)
+		default:
+			debian, found := r.debianPackage(name)
+			if !found {
+				r.unmapped(name)
+				debian = name
+			}
+			if !containsString(names, debian) {
+				names = append(names, debian)
+			}
+		}
+	}
+	return names, groups, true
+}
+
+// debianPackage returns the Debian name of a Fedora package. The package
+// table is consulted first, then -devel packages are given Debian's -dev
+// name for libraries, which the report lists as guesses. ok is false when
+// no mapping is known.
+func (r *rewriter) debianPackage(name string) (string, bool) {
+	if debian, ok := debianPackages[name]; ok {
+		return debian, true
+	}
+	if base := strings.TrimSuffix(name, "-devel"); base != name && base != "" {
+		if !strings.HasPrefix(base, "lib") {
+			base = "lib" + base
+		}
+		r.guessed(name, base+"-dev")
+		return base + "-dev", true
+	}
+	return "", false
+}
+
+// rewriteDnfGroup converts the installation and removal of package groups
+// to the Debian meta-packages mapped to them.
+func (r *rewriter) rewriteDnfGroup(cmd command, inv dnfInvocation, action string, operands []*syntax.Word) {
+	sub := map[string]string{"install": "install", "remove": "remove", "erase": "remove"}[action]
+	if sub == "" || len(operands) == 0 {
+		r.stubForUbuntu(cmd)
+		return
+	}
+
+	var metas []string
+	for _, operand := range operands {
+		id, _ := literal(operand)
+		meta, ok := debianGroups[strings.TrimPrefix(id, "@")]
+		if !ok {
+			r.stub(cmd, fmt.Sprintf("no Ubuntu meta-package known for the dnf group %s", r.text(operand)))
+			return
+		}
+		if !containsString(metas, meta) {
+			metas = append(metas, meta)
+		}
+	}
+
+	options := r.aptOptions(cmd, dnfInvocation{options: withoutOption(inv.options, "--with-optional")})
+	r.replaceCommand(cmd, joinWords("apt-get "+sub, options, metas), false)
+}
+
+// withoutOption returns options without flag, which needs no conversion.
+func withoutOption(options []string, flag string) []string {
+	var kept []string
+	for _, opt := range options {
+		if opt != flag {
+			kept = append(kept, opt)
+		}
+	}
+	return kept
+}
+
+// rewriteCopr converts dnf copr enable and remove to add-apt-repository
+// for the PPA the catalog knows for the COPR project, and to a failing
+// stub otherwise.
+func (r *rewriter) rewriteCopr(cmd command, inv dnfInvocation) {
+	action, operands := inv.action()
+	if len(operands) == 0 {
+		r.stubForUbuntu(cmd)
+		return
+	}
+	project, _ := literal(operands[0])
+
+	var flag string
+	switch action {
+	case "enable":
+	case "remove", "disable":
+		flag = "-r "
+	default:
+		r.stubForUbuntu(cmd)
+		return
+	}
+	ppa, ok := coprPPAs[project]
+	if !ok {
+		r.stub(cmd, fmt.Sprintf("no PPA known for the COPR project %s", project))
+		return
+	}
+	r.replaceCommand(cmd, "add-apt-repository "+flag+"-y ppa:"+ppa, false)
+}
+
+// rewriteConfigManager converts the addition of a .repo file by URL, with
+// dnf4's --add-repo or dnf5's addrepo --from-repofile, to an apt source.
+func (r *rewriter) rewriteConfigManager(cmd command, inv dnfInvocation) {
+	url, ok := inv.option("--add-repo")
+	if !ok && inv.subcommand == "config-manager" {
+		if action, _ := inv.action(); action == "addrepo" {
+			url, ok = inv.option("--from-repofile")
+		}
+	}
+	if !ok {
+		r.stubForUbuntu(cmd)
+		return
+	}
+
+	entry, known := lookupAptRepo(url)
+	if !known {
+		r.stub(cmd, fmt.Sprintf("no apt repository known for %s; add a sources.list.d entry manually", url))
+		return
+	}
+	id := strings.TrimSuffix(path.Base(url), ".repo")
+	r.replace(cmd.call, r.aptSourceCommand(id, []aptRepo{{entry: entry, gpgkey: entry["fedora_gpgkey"]}}, true))
+}
+
+// rewriteDnfMark converts holds with the versionlock plugin and changes of
+// the install reason with dnf mark to apt-mark.
+func (r *rewriter) rewriteDnfMark(cmd command, inv dnfInvocation) {
+	action, operands := inv.action()
+	sub, ok := map[string]string{
+		"versionlock add":    "hold",
+		"versionlock delete": "unhold",
+		"versionlock list":   "showhold",
+		"mark install":       "manual",
+		"mark user":          "manual",
+		"mark remove":        "auto",
+		"mark dependency":    "auto",
+	}[inv.subcommand+" "+action]
+	if action == "" && inv.subcommand == "versionlock" {
+		sub, ok = "showhold", true
+	}
+	if !ok {
+		r.stubForUbuntu(cmd)
+		return
+	}
+
+	var names []string
+	for _, operand := range operands {
+		name, isLit := literal(operand)
+		if debian, found := r.debianPackage(name); isLit && found {
+			name = debian
+		} else if isLit {
+			r.unmapped(name)
+		} else {
+			name = r.text(operand)
+		}
+		names = append(names, name)
+	}
+	r.replaceCommand(cmd, joinWords("apt-mark "+sub, nil, names), false)
+}
+
+// rewriteRPM converts rpm's queries to dpkg, the import of signing keys
+// to an apt keyring and rpm -E %fedora to the Ubuntu release number.
+func (r *rewriter) rewriteRPM(cmd command) {
+	var letters string
+	quiet := false
+	var operands []*syntax.Word
+	for i := 0; i < len(cmd.args); i++ {
+		lit := cmd.args[i].Lit()
+		switch {
+		case lit == "--import":
+			r.rewriteRPMImport(cmd, cmd.args[i+1:])
+			return
+		case lit == "-E" || lit == "--eval":
+			if i+1 < len(cmd.args) && cmd.args[i+1].Lit() == "%fedora" {
+				r.replaceCommand(cmd, "lsb_release -rs", false)
+				return
+			}
+			r.stubForUbuntu(cmd)
+			return
+		case lit == "--quiet":
+			quiet = true
+		case strings.HasPrefix(lit, "--"):
+			r.stubForUbuntu(cmd)
+			return
+		case strings.HasPrefix(lit, "-"):
+			letters += lit[1:]
+		default:
+			operands = append(operands, cmd.args[i])
+		}
+	}
+
+	var text string
+	switch {
+	case !strings.HasPrefix(letters, "q"):
+		r.stubForUbuntu(cmd)
+		return
+	case strings.Contains(letters, "a"):
+		text = `dpkg-query -W -f='${Package}\n'`
+		operands = nil
+	case strings.Contains(letters, "f"):
+		text = "dpkg -S " + r.wordsText(operands)
+		operands = nil
+	case strings.Contains(letters, "l"):
+		text = "dpkg -L"
+	default:
+		text = "dpkg -s"
+	}
+	for _, operand := range operands {
+		name, isLit := literal(operand)
+		if debian, found := r.debianPackage(name); isLit && found {
+			name = debian
+		} else if isLit {
+			r.unmapped(name)
+		} else {
+			name = r.text(operand)
+		}
+		text += " " + name
+	}
+	if quiet {
+		text += " > /dev/null 2>&1"
+	}
+	r.replaceCommand(cmd, text, false)
+}
+
+// rewriteRPMImport converts rpm --import. Keys of the repositories the
+// catalog knows are saved with their apt source, so the import is dropped;
+// other keys are saved to a keyring for a signed-by option to refer to.
+func (r *rewriter) rewriteRPMImport(cmd command, keys []*syntax.Word) {
+	var parts []string
+	for _, key := range keys {
+		source, _ := r.template(key)
+		if _, known := lookupAptKey(source); known {
+			continue
+		}
+		keyring := path.Join(aptKeyringDir, strings.TrimSuffix(path.Base(source), path.Ext(source))+".gpg")
+		parts = append(parts, r.keyringCommand(source, keyring, r.sudoText(cmd)))
+		r.warn(cmd.call, "saved the signing key %s to %s; refer to it with signed-by in the apt source", source, keyring)
+	}
+	if len(parts) == 0 {
+		r.replace(cmd.call, "true")
+		r.warn(cmd.call, "removed rpm --import: the key is saved with its apt source")
+		return
+	}
+	r.replace(cmd.call, strings.Join(parts, " && "))
+}
+
+// aptRepo is a repository of a .repo file matched to a catalog entry,
+// with the signing key the file gives.
+type aptRepo struct {
+	entry  map[string]string
+	gpgkey string
+}
+
+// rewriteRepoFile converts a statement writing a .repo file into commands
+// saving the signing keys to keyrings and writing an apt source list
+// referring to them, and reports whether stmt was rewritten.
+func (r *rewriter) rewriteRepoFile(stmt *syntax.Stmt) bool {
+	w, ok := r.writtenFile(stmt, yumReposDir)
+	if !ok {
+		return false
+	}
+	sections := parseRepoFile(w.content)
+	if len(sections) == 0 {
+		return false
+	}
+
+	var known []aptRepo
+	var unknown []string
+	for _, repo := range sections {
+		entry, found := lookupAptRepo(repo.BaseURL)
+		if !found {
+			unknown = append(unknown, repo.BaseURL)
+			continue
+		}
+		gpgkey := repo.GPGKey
+		if gpgkey == "" {
+			gpgkey = entry["fedora_gpgkey"]
+		}
+		known = append(known, aptRepo{entry: entry, gpgkey: gpgkey})
+	}
+
+	id := strings.TrimSuffix(path.Base(w.target), ".repo")
+	if len(known) == 0 {
+		msg := fmt.Sprintf("no apt repository known for %s; add a sources.list.d entry manually", unknown[0])
+		r.replaceFileWrite(w, stubText(msg))
+		r.warn(stmt, "%s", msg)
+		return true
+	}
+	for _, baseurl := range unknown {
+		r.warn(stmt, "dropped repository %s: no apt repository is known for it", baseurl)
+	}
+	r.replaceFileWrite(w, r.aptSourceCommand(id, known, w.sudo))
+	return true
+}
+
+// parseRepoFile reads the enabled repositories of a .repo file.
+func parseRepoFile(content string) []Repo {
+	var repos []Repo
+	enabled := true
+	var repo *Repo
+	flush := func() {
+		if repo != nil && repo.BaseURL != "" && enabled {
+			repos = append(repos, *repo)
+		}
+	}
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			flush()
+			repo = &Repo{ID: strings.Trim(line, "[]")}
+			enabled = true
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found || repo == nil {
+			continue
+		}
+		// Unquoted heredocs escape the $ of dnf variables.
+		value = strings.ReplaceAll(strings.TrimSpace(value), `\$`, "$")
+		switch strings.TrimSpace(key) {
+		case "name":
+			repo.Name = value
+		case "baseurl":
+			repo.BaseURL = strings.Fields(value + " ")[0]
+		case "gpgkey":
+			repo.GPGKey = strings.Fields(value + " ")[0]
+		case "enabled":
+			enabled = value != "0"
+		}
+	}
+	flush()
+	return repos
+}
+
+// aptSourceCommand builds the commands saving the signing keys of repos
+// to /etc/apt/keyrings and writing /etc/apt/sources.list.d/<id>.list with
+// signed-by options referring to them.
+func (r *rewriter) aptSourceCommand(id string, repos []aptRepo, sudo bool) string {
+	sudoText := ""
+	if sudo {
+		sudoText = "sudo "
+	}
+
+	var parts, lines []string
+	keyrings := make(map[string]string)
+	for _, repo := range repos {
+		keyring, saved := keyrings[repo.gpgkey]
+		if !saved && repo.gpgkey != "" {
+			keyring = path.Join(aptKeyringDir, id+".gpg")
+			if len(keyrings) > 0 {
+				keyring = path.Join(aptKeyringDir, fmt.Sprintf("%s-%d.gpg", id, len(keyrings)+1))
+			}
+			keyrings[repo.gpgkey] = keyring
+			parts = append(parts, r.keyringCommand(repo.gpgkey, keyring, sudoText))
+		}
+		options := "arch=$(dpkg --print-architecture)"
+		if keyring != "" {
+			options += " signed-by=" + keyring
+		}
+		lines = append(lines, fmt.Sprintf("deb [%s] %s", options, repo.entry["deb"]))
+	}
+
+	list := path.Join(aptListDir, id+".list")
+	parts = append(parts, fmt.Sprintf(`echo "%s" | %stee %s > /dev/null`, strings.Join(lines, "\n"), sudoText, list))
+	return strings.Join(parts, " && ")
+}
+
+// keyringCommand returns the command saving the key at source, a URL or a
+// file, as a binary keyring.
+func (r *rewriter) keyringCommand(source, keyring, sudo string) string {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		return fmt.Sprintf("curl -fsSL %s | %sgpg --dearmor --yes -o %s", source, sudo, keyring)
+	}
+	return fmt.Sprintf("%sgpg --dearmor --yes -o %s %s", sudo, keyring, strings.TrimPrefix(source, "file://"))
+}
+
+// lookupAptRepo returns the catalog entry of the vendor whose Fedora
+// repository is at url, either its baseurl or the URL of a .repo file
+// next to or below the repository. Only entries with an apt source are
+// considered.
+func lookupAptRepo(url string) (map[string]string, bool) {
+	url = strings.TrimSuffix(url, "/")
+	keys := make([]string, 0, len(repos.Repos))
+	for key, entry := range repos.Repos {
+		if entry["fedora"] != "" && entry["deb"] != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if strings.TrimSuffix(repos.Repos[key]["fedora"], "/") == url {
+			return repos.Repos[key], true
+		}
+	}
+	if !strings.HasSuffix(url, ".repo") {
+		return nil, false
+	}
+	for _, key := range keys {
+		baseurl := strings.TrimSuffix(repos.Repos[key]["fedora"], "/")
+		stem := strings.TrimSuffix(strings.SplitN(baseurl, "$", 2)[0], "/")
+		dir := path.Dir(url)
+		if strings.HasPrefix(url, stem+"/") || baseurl == dir || strings.HasPrefix(baseurl, dir+"/") {
+			return repos.Repos[key], true
+		}
+	}
+	return nil, false
+}
+
+// lookupAptKey returns the catalog entry whose Fedora signing key is key.
+func lookupAptKey(key string) (map[string]string, bool) {
+	for _, entry := range repos.Repos {
+		if entry["deb"] != "" && entry["fedora_gpgkey"] == key {
+			return entry, true
+		}
+	}
+	return nil, false
+}
+
+// stubForUbuntu replaces a command the reverse conversion does not cover
+// with a failing stub.
+func (r *rewriter) stubForUbuntu(cmd command) {
+	r.stub(cmd, fmt.Sprintf("cannot convert %s %s for Ubuntu", cmd.name.Lit(), strings.TrimSpace(r.wordsText(cmd.args))))
+}
+
+// joinWords joins a command with its options and operands.
+func joinWords(command string, options, operands []string) string {
+	words := append([]string{command}, options...)
+	return strings.Join(append(words, operands...), " ")
+}
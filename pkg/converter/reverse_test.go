@@ -0,0 +1,197 @@
+package converter_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ubuntu-to-fedora/pkg/converter"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// convertFedoraScript writes a Fedora script to a temporary directory,
+// converts it for Ubuntu and returns the result without its header
+func convertFedoraScript(t *testing.T, script string) (string, converter.Report) {
+	t.Helper()
+	tempDir := t.TempDir()
+	scriptPath := filepath.Join(tempDir, "script.sh")
+	if err := os.WriteFile(scriptPath, []byte(script), 0644); err != nil {
+		t.Fatalf("Failed to write test script: %v", err)
+	}
+
+	reports, err := converter.ConvertFedoraDir(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to convert test script: %v", err)
+	}
+
+	converted, err := os.ReadFile(scriptPath)
+	if err != nil {
+		t.Fatalf("Failed to read converted script: %v", err)
+	}
+	return withoutHeader(string(converted)), reports[0]
+}
+
+// TestReverseConversion tests the conversion of Fedora scripts for Ubuntu
+func TestReverseConversion(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		warning  string
+	}{
+		{
+			name:     "Install with options",
+			input:    "sudo dnf install -y --setopt=install_weak_deps=False curl",
+			expected: "sudo apt-get install -y --no-install-recommends curl",
+		},
+		{
+			name:     "Package names from the catalog",
+			input:    "sudo dnf install -y gnome-tweaks python3 kernel",
+			expected: "sudo apt-get install -y gnome-tweaks python3 linux-generic",
+		},
+		{
+			name:     "Development packages",
+			input:    "sudo dnf install -y openssl-devel foo-devel libbar-devel",
+			expected: "sudo apt-get install -y libssl-dev libfoo-dev libbar-dev",
+		},
+		{
+			name:     "Upgrades refresh the package lists first",
+			input:    "sudo dnf upgrade -y",
+			expected: "sudo apt-get update && sudo apt-get upgrade -y",
+		},
+		{
+			name:     "Negated upgrade",
+			input:    "if ! sudo dnf upgrade -y > /dev/null; then exit 1; fi",
+			expected: "if ! { sudo apt-get update && sudo apt-get upgrade -y; } > /dev/null; then exit 1; fi",
+		},
+		{
+			name:     "Piped upgrade",
+			input:    "sudo dnf upgrade -y | tee upgrade.log",
+			expected: "{ sudo apt-get update && sudo apt-get upgrade -y; } | tee upgrade.log",
+		},
+		{
+			name:     "yum and clean",
+			input:    "yum clean all",
+			expected: "apt-get clean",
+		},
+		{
+			name:     "dnf-only option",
+			input:    "sudo dnf install -y --refresh git",
+			expected: "sudo apt-get install -y git",
+			warning:  "dropped dnf option --refresh: apt has no equivalent",
+		},
+		{
+			name:     "Package groups",
+			input:    "sudo dnf group install -y development-tools c-development\nsudo dnf install -y @multimedia",
+			expected: "sudo apt-get install -y build-essential\nsudo apt-get install -y ubuntu-restricted-extras",
+		},
+		{
+			name:     "RPM Fusion is dropped",
+			input:    "sudo dnf install -y https://mirrors.rpmfusion.org/free/fedora/rpmfusion-free-release-$(rpm -E %fedora).noarch.rpm",
+			expected: "true",
+			warning:  "removed https://mirrors.rpmfusion.org/free/fedora/rpmfusion-free-release-$(rpm -E %fedora).noarch.rpm: no packages are needed on Ubuntu",
+		},
+		{
+			name:     "Known COPR project",
+			input:    "sudo dnf copr enable -y atim/lazygit",
+			expected: "sudo add-apt-repository -y ppa:lazygit-team/release",
+		},
+		{
+			name:     "Unknown COPR project",
+			input:    "sudo dnf copr enable -y someone/tool",
+			expected: "{ echo 'no PPA known for the COPR project someone/tool' >&2; false; }",
+			warning:  "no PPA known for the COPR project someone/tool",
+		},
+		{
+			name:     "Repository file by URL",
+			input:    "sudo dnf config-manager --add-repo https://cli.github.com/packages/rpm/gh-cli.repo",
+			expected: "curl -fsSL https://cli.github.com/packages/rpm/gh-cli.repo.asc | sudo gpg --dearmor --yes -o /etc/apt/keyrings/gh-cli.gpg && echo \"deb [arch=$(dpkg --print-architecture) signed-by=/etc/apt/keyrings/gh-cli.gpg] https://cli.github.com/packages stable main\" | sudo tee /etc/apt/sources.list.d/gh-cli.list > /dev/null",
+		},
+		{
+			name:     "Repository file written by the script",
+			input:    "sudo tee /etc/yum.repos.d/docker-ce.repo <<EOF\n[docker-ce-stable]\nname=Docker CE Stable\nbaseurl=https://download.docker.com/linux/fedora/$releasever/$basearch/stable\nenabled=1\ngpgcheck=1\ngpgkey=https://download.docker.com/linux/fedora/gpg\nEOF\n",
+			expected: "curl -fsSL https://download.docker.com/linux/fedora/gpg | sudo gpg --dearmor --yes -o /etc/apt/keyrings/docker-ce.gpg && echo \"deb [arch=$(dpkg --print-architecture) signed-by=/etc/apt/keyrings/docker-ce.gpg] https://download.docker.com/linux/ubuntu $(lsb_release -cs) stable\" | sudo tee /etc/apt/sources.list.d/docker-ce.list > /dev/null\n",
+		},
+		{
+			name:     "Key of a known repository",
+			input:    "sudo rpm --import https://packages.microsoft.com/keys/microsoft.asc",
+			expected: "true",
+			warning:  "removed rpm --import: the key is saved with its apt source",
+		},
+		{
+			name:     "rpm queries",
+			input:    "rpm -q --quiet gnome-tweaks || rpm -ql curl",
+			expected: "dpkg -s gnome-tweaks > /dev/null 2>&1 || dpkg -L curl",
+		},
+		{
+			name:     "Release number",
+			input:    "VERSION=$(rpm -E %fedora)",
+			expected: "VERSION=$(lsb_release -rs)",
+		},
+		{
+			name:     "Holds",
+			input:    "sudo dnf install -y 'dnf-command(versionlock)' && sudo dnf versionlock add firefox",
+			expected: "true && sudo apt-mark hold firefox",
+			warning:  "removed dnf-command(versionlock): apt needs no dnf plugins",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converted, report := convertFedoraScript(t, tt.input)
+			assert.Equal(t, tt.expected, converted, "Commands should be converted for Ubuntu")
+			if tt.warning != "" {
+				var messages []string
+				for _, d := range report.Diagnostics {
+					messages = append(messages, d.Message)
+				}
+				assert.Contains(t, messages, tt.warning, "The conversion should be reported")
+			}
+		})
+	}
+}
+
+// TestRoundTrip tests that converting for Fedora and back gives the
+// original commands, as both directions read the same data files
+func TestRoundTrip(t *testing.T) {
+	scripts := []string{
+		"sudo apt-get install -y curl gnome-tweaks libssl-dev",
+		"sudo apt-get install -y build-essential",
+		"sudo add-apt-repository -y ppa:lazygit-team/release",
+		"sudo apt-get remove -y mupdf",
+		"sudo apt-get autoremove -y",
+	}
+
+	for _, script := range scripts {
+		t.Run(script, func(t *testing.T) {
+			fedora, _ := convertScript(t, script)
+			ubuntu, _ := convertFedoraScript(t, fedora)
+			assert.Equal(t, script, ubuntu, "Converting %q back should give the original", fedora)
+		})
+	}
+}
+
+// TestReverseSkipsConvertedScripts tests that scripts converted for Fedora
+// by the tool are not converted back in place
+func TestReverseSkipsConvertedScripts(t *testing.T) {
+	tempDir := t.TempDir()
+	scriptPath := filepath.Join(tempDir, "script.sh")
+	if err := os.WriteFile(scriptPath, []byte("sudo apt install -y curl\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test script: %v", err)
+	}
+	if _, err := converter.ConvertDir(tempDir); err != nil {
+		t.Fatalf("Failed to convert test script: %v", err)
+	}
+
+	reports, err := converter.ConvertFedoraDir(tempDir)
+	assert.NoError(t, err, "Expected no error during conversion")
+	assert.Equal(t, "fedora", reports[0].ConvertedFor, "The script should be reported as converted for Fedora")
+}
+
+// TestReverseReportsGuesses tests that Debian names derived from -devel
+// packages are reported as guesses
+func TestReverseReportsGuesses(t *testing.T) {
+	_, report := convertFedoraScript(t, "sudo dnf install -y openssl-devel foo-devel")
+	assert.Equal(t, []string{"foo-devel -> libfoo-dev"}, report.Guessed, "Expected only the guessed name to be reported")
+}
@@ -0,0 +1,612 @@
+package converter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// edit replaces the bytes between start and end of the original script.
+type edit struct {
+	start uint
+	end   uint
+	text  string
+}
+
+// rewriter collects edits against a parsed script. Only the byte ranges
+// covered by edits change; everything else is copied through verbatim.
+type rewriter struct {
+	src    []byte
+	edits  []edit
+	report Report
+	target Target
+
+	// keyrings tracks signing keys saved to keyring files, by path, so
+	// repositories referring to them with signed-by can use the key URL.
+	keyrings     map[string]*keyring
+	keyringOrder []string
+
+	// debs tracks downloaded .deb files by file name.
+	debs map[string]*debDownload
+
+	// assigns tracks variable assignments by name, and archVars and
+	// archiveVars the variables expanded in download URLs and in archive
+	// URLs. printArches holds the dpkg --print-architecture commands, and
+	// archiveArches those whose output ends up in an archive URL.
+	assigns       map[string][]assignment
+	archVars      map[string]bool
+	archiveVars   map[string]bool
+	printArches   []command
+	archiveArches map[*syntax.CallExpr]bool
+
+	// flathubAdded is set once a converted command adds the Flathub remote,
+	// versionlockInstalled and dnfAutomaticInstalled once one installs the
+	// versionlock plugin or dnf-automatic.
+	flathubAdded          bool
+	versionlockInstalled  bool
+	dnfAutomaticInstalled bool
+
+	// installedAlternatives records the alternatives the script installs
+	// with update-alternatives --install.
+	installedAlternatives map[string]bool
+
+	// enabledRepos records the third-party repositories, such as RPM
+	// Fusion, that converted commands have already enabled.
+	enabledRepos map[string]bool
+
+	// installNoteAt is the first command of the script that installs
+	// packages, where the target's note on installs is reported.
+	installNoteAt syntax.Node
+
+	// topLevelCalls holds the commands that run unconditionally, and
+	// installBatch the installs a batchInstallTarget merges into the last
+	// of them.
+	topLevelCalls map[*syntax.CallExpr]bool
+	installBatch  []batchedInstall
+
+	// nestedStmts holds the statements that are part of a pipeline or an
+	// && or || list, and groupedCalls the commands whose statement binds
+	// them to something else, such as a !, a redirection or a list. A
+	// replacement running several commands is grouped with braces there.
+	nestedStmts  map[*syntax.Stmt]bool
+	groupedCalls []*syntax.CallExpr
+
+	// flaggedPipelines holds the first stage of pipelines whose parsed
+	// output was reported, as a pipeline nests the shorter ones before it.
+	flaggedPipelines map[*syntax.Stmt]bool
+}
+
+// command is a simple command split into its privilege prefix (sudo and
+// its flags), the command name and the remaining arguments.
+type command struct {
+	call   *syntax.CallExpr
+	prefix []*syntax.Word
+	name   *syntax.Word
+	args   []*syntax.Word
+}
+
+// parseScript parses a script as bash, keeping its comments.
+func parseScript(src []byte) (*syntax.File, error) {
+	file, err := syntax.NewParser(syntax.KeepComments(true), syntax.Variant(syntax.LangBash)).
+		Parse(bytes.NewReader(src), "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse script: %w", err)
+	}
+	return file, nil
+}
+
+// errorLine returns the line a parse error was found on, or 0 for other
+// errors.
+func errorLine(err error) uint {
+	var perr syntax.ParseError
+	if errors.As(err, &perr) {
+		return perr.Pos.Line()
+	}
+	return 0
+}
+
+func convertScript(src []byte, target Target) ([]byte, Report, error) {
+	file, err := parseScript(src)
+	if err != nil {
+		return nil, Report{}, err
+	}
+
+	r := &rewriter{src: src, target: target}
+	r.trackTopLevel(file)
+	syntax.Walk(file, func(node syntax.Node) bool {
+		switch node := node.(type) {
+		case *syntax.Stmt:
+			r.trackGrouping(node)
+			return !r.rewriteStmt(node)
+		case *syntax.BinaryCmd:
+			r.trackNested(node)
+		case *syntax.CallExpr:
+			if cmd, ok := splitCommand(node); ok {
+				r.rewriteCommand(cmd)
+			}
+		case *syntax.Assign:
+			r.trackAssign(node)
+		case *syntax.ParamExp:
+			r.rewriteCodenameParam(node)
+		case *syntax.BinaryTest:
+			x, xok := node.X.(*syntax.Word)
+			y, yok := node.Y.(*syntax.Word)
+			if xok && yok {
+				r.rewriteReleaseTest(x, y)
+			}
+		case *syntax.CaseClause:
+			r.rewriteReleaseCase(node)
+		}
+		return true
+	})
+
+	r.finishKeyrings()
+	r.finishArchVars()
+	r.finishInstalls()
+	r.finishGrouping()
+	return r.apply(), r.report, nil
+}
+
+// splitCommand separates a leading sudo from the command it runs. Calls
+// whose command name is not a plain literal are ignored.
+func splitCommand(call *syntax.CallExpr) (command, bool) {
+	args := call.Args
+	i := 0
+	if i < len(args) && args[i].Lit() == "sudo" {
+		i++
+		for i < len(args) && strings.HasPrefix(args[i].Lit(), "-") {
+			i++
+		}
+	}
+	if i >= len(args) || args[i].Lit() == "" {
+		return command{}, false
+	}
+
+	return command{
+		call:   call,
+		prefix: args[:i],
+		name:   args[i],
+		args:   args[i+1:],
+	}, true
+}
+
+func (c command) sudo() bool {
+	return len(c.prefix) > 0
+}
+
+// rewriteStmt handles constructs spanning more than a single command and
+// reports whether stmt was rewritten as a whole.
+func (r *rewriter) rewriteStmt(stmt *syntax.Stmt) bool {
+	return r.rewriteAptSource(stmt) || r.rewriteUnattendedConfig(stmt) || r.rewriteKeyImport(stmt) ||
+		r.rewriteDpkgPipeline(stmt) || r.flagAptQueryPipeline(stmt)
+}
+
+func (r *rewriter) rewriteCommand(cmd command) {
+	switch cmd.name.Lit() {
+	case "apt", "apt-get":
+		r.rewriteApt(cmd)
+	case "apt-cache":
+		r.rewriteAptCache(cmd)
+	case "add-apt-repository":
+		r.rewriteAddAptRepository(cmd)
+	case "apt-key":
+		r.rewriteAptKey(cmd)
+	case "curl", "wget":
+		r.trackDebDownload(cmd)
+		r.rewriteDownloadArch(cmd)
+	case "dpkg", "dpkg-query":
+		r.rewriteDpkg(cmd)
+	case "gdebi":
+		r.rewriteDebTool(cmd)
+	case "rm":
+		r.rewriteDebCleanup(cmd)
+	case "install", "cp", "mv":
+		r.rewriteKeyringCopy(cmd)
+	case "update-alternatives":
+		r.rewriteUpdateAlternatives(cmd)
+	case "apt-mark":
+		r.rewriteAptMark(cmd)
+	case "dpkg-reconfigure":
+		r.rewriteDpkgReconfigure(cmd)
+	case "systemctl":
+		r.rewriteSystemctlUnits(cmd)
+	case "tasksel":
+		r.rewriteTasksel(cmd)
+	case "ufw":
+		r.rewriteUfw(cmd)
+	case "snap":
+		r.rewriteSnap(cmd)
+	case "lsb_release":
+		r.rewriteLsbRelease(cmd)
+	case "[", "test":
+		r.rewriteTestCommand(cmd)
+	}
+}
+
+func (r *rewriter) replace(node syntax.Node, text string) {
+	r.edits = append(r.edits, edit{
+		start: node.Pos().Offset(),
+		end:   node.End().Offset(),
+		text:  text,
+	})
+}
+
+// replaceCommand replaces the name and arguments of cmd with text, keeping
+// its sudo prefix. When privileged is set and cmd was not run through
+// sudo, the replacement gains one.
+func (r *rewriter) replaceCommand(cmd command, text string, privileged bool) {
+	if privileged && !cmd.sudo() {
+		text = "sudo " + text
+	}
+	r.edits = append(r.edits, edit{
+		start: cmd.name.Pos().Offset(),
+		end:   cmd.call.End().Offset(),
+		text:  text,
+	})
+}
+
+// prefixText returns the sudo prefix of cmd as written, followed by a
+// space, for replacements that run several commands with the same privilege.
+func (r *rewriter) prefixText(cmd command) string {
+	if !cmd.sudo() {
+		return ""
+	}
+	return r.wordsText(cmd.prefix) + " "
+}
+
+// sudoText is prefixText for replacements that need root, falling back to
+// plain sudo when cmd was not run through it.
+func (r *rewriter) sudoText(cmd command) string {
+	if !cmd.sudo() {
+		return "sudo "
+	}
+	return r.prefixText(cmd)
+}
+
+// rpm reports whether the target's packages are RPMs, which rpm queries.
+func (r *rewriter) rpm() bool {
+	_, ok := r.target.(rpmTarget)
+	return ok
+}
+
+// dnf reports whether the target installs packages with dnf. Conversions
+// to dnf tools other than the package manager itself only apply then.
+func (r *rewriter) dnf() bool {
+	_, ok := r.target.(dnfTarget)
+	return ok
+}
+
+// released reports whether the target has numbered releases whose number
+// rpm prints.
+func (r *rewriter) released() bool {
+	_, ok := r.target.(releaseTarget)
+	return ok
+}
+
+// releaseMacro returns the rpm macro holding the release number of a
+// target with numbered releases.
+func (r *rewriter) releaseMacro() string {
+	return r.target.(releaseTarget).releaseMacro()
+}
+
+// impliedOption reports whether the package manager behaves as if given
+// the apt option flag.
+func (r *rewriter) impliedOption(flag string) bool {
+	t, ok := r.target.(impliedOptionTarget)
+	return ok && t.Implied(flag)
+}
+
+// noteInstall records the first command of the script that installs
+// packages, for finishInstalls to report the target's note on it.
+func (r *rewriter) noteInstall(at syntax.Node) {
+	if r.installNoteAt == nil {
+		r.installNoteAt = at
+	}
+}
+
+// grammar returns the spelling of the commands of a dnf target.
+func (r *rewriter) grammar() *dnfGrammar {
+	return r.target.(dnfTarget).grammar()
+}
+
+// requireRPM and requireDNF stub cmd when the target does not use rpm or
+// dnf, and report whether the conversion can go ahead.
+func (r *rewriter) requireRPM(cmd command) bool {
+	return r.require(cmd, r.rpm())
+}
+
+func (r *rewriter) requireDNF(cmd command) bool {
+	return r.require(cmd, r.dnf())
+}
+
+func (r *rewriter) require(cmd command, supported bool) bool {
+	if supported {
+		return true
+	}
+	r.stub(cmd, fmt.Sprintf("cannot convert %s %s for %s", cmd.name.Lit(), strings.TrimSpace(r.wordsText(cmd.args)), r.target.Name()))
+	return false
+}
+
+// stub replaces the whole of cmd with a command that prints msg and fails,
+// so the converted script stops where a manual fix is needed.
+func (r *rewriter) stub(cmd command, msg string) {
+	r.replace(cmd.call, stubText(msg))
+	r.warn(cmd.call, "%s", msg)
+}
+
+// stubText returns a command that prints msg and fails.
+func stubText(msg string) string {
+	return fmt.Sprintf("{ echo %s >&2; false; }", shellQuote(msg))
+}
+
+// trackNested records the statements joined by a pipe or an && or ||
+// list.
+func (r *rewriter) trackNested(cmd *syntax.BinaryCmd) {
+	if r.nestedStmts == nil {
+		r.nestedStmts = make(map[*syntax.Stmt]bool)
+	}
+	r.nestedStmts[cmd.X] = true
+	r.nestedStmts[cmd.Y] = true
+}
+
+// trackTopLevel records the commands of file that run unconditionally:
+// the commands of its top-level statements, and apt commands joined by
+// && to nothing but other apt commands, as in "apt update && apt install".
+func (r *rewriter) trackTopLevel(file *syntax.File) {
+	r.topLevelCalls = make(map[*syntax.CallExpr]bool)
+	for _, stmt := range file.Stmts {
+		if stmt.Negated || stmt.Background || stmt.Coprocess {
+			continue
+		}
+		if call, ok := stmt.Cmd.(*syntax.CallExpr); ok {
+			r.topLevelCalls[call] = true
+			continue
+		}
+		if calls, ok := aptChain(stmt); ok {
+			for _, call := range calls {
+				r.topLevelCalls[call] = true
+			}
+		}
+	}
+}
+
+// aptChain returns the commands of a statement made of apt commands joined
+// by &&.
+func aptChain(stmt *syntax.Stmt) ([]*syntax.CallExpr, bool) {
+	if stmt.Negated || stmt.Background || stmt.Coprocess {
+		return nil, false
+	}
+	switch cmd := stmt.Cmd.(type) {
+	case *syntax.CallExpr:
+		c, ok := splitCommand(cmd)
+		if !ok || (c.name.Lit() != "apt" && c.name.Lit() != "apt-get") {
+			return nil, false
+		}
+		return []*syntax.CallExpr{cmd}, true
+	case *syntax.BinaryCmd:
+		if cmd.Op != syntax.AndStmt {
+			return nil, false
+		}
+		x, xok := aptChain(cmd.X)
+		y, yok := aptChain(cmd.Y)
+		return append(x, y...), xok && yok
+	}
+	return nil, false
+}
+
+// trackGrouping records the command of stmt when a replacement running
+// several commands would not be bound as a whole to stmt's negation,
+// redirections or list.
+func (r *rewriter) trackGrouping(stmt *syntax.Stmt) {
+	call, ok := stmt.Cmd.(*syntax.CallExpr)
+	if !ok {
+		return
+	}
+	if stmt.Negated || stmt.Background || stmt.Coprocess || len(stmt.Redirs) > 0 || r.nestedStmts[stmt] {
+		r.groupedCalls = append(r.groupedCalls, call)
+	}
+}
+
+// finishGrouping wraps the replacements of the commands recorded by
+// trackGrouping in braces when they run several commands, as in
+// "if ! { a && b; }; then".
+func (r *rewriter) finishGrouping() {
+	for _, call := range r.groupedCalls {
+		start, end := call.Pos().Offset(), call.End().Offset()
+		name := start
+		if cmd, ok := splitCommand(call); ok {
+			name = cmd.name.Pos().Offset()
+		}
+		multi, covered := false, false
+		for _, e := range r.edits {
+			whole := e.end == end && (e.start == start || e.start == name)
+			before := e.start == start && e.end == start
+			after := e.start == end && e.end == end
+			if (whole || before || after) && multiCommand(e.text) {
+				multi = true
+			}
+			if e.start <= start && e.end >= end && (e.start < start || e.end > end) {
+				covered = true
+			}
+		}
+		if !multi || covered {
+			continue
+		}
+		// The opening brace goes before any other insertion at the start.
+		r.edits = append([]edit{{start: start, end: start, text: "{ "}}, r.edits...)
+		r.edits = append(r.edits, edit{start: end, end: end, text: "; }"})
+	}
+}
+
+// multiCommand reports whether the shell text runs several commands at
+// its top level, joined by &&, ||, ; or a pipe.
+func multiCommand(text string) bool {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' {
+				i++
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '\\':
+			i++
+		case c == '{' || c == '(':
+			depth++
+		case c == '}' || c == ')':
+			depth--
+		case depth > 0:
+		case c == ';' || c == '|':
+			return true
+		case c == '&' && i+1 < len(text) && text[i+1] == '&':
+			return true
+		}
+	}
+	return false
+}
+
+// text returns the original source of node.
+func (r *rewriter) text(node syntax.Node) string {
+	return string(r.src[node.Pos().Offset():node.End().Offset()])
+}
+
+// removeArg deletes an argument of call together with the whitespace
+// separating it from the previous argument.
+func (r *rewriter) removeArg(call *syntax.CallExpr, word *syntax.Word) {
+	for i, arg := range call.Args {
+		if arg == word && i > 0 {
+			r.edits = append(r.edits, edit{
+				start: call.Args[i-1].End().Offset(),
+				end:   word.End().Offset(),
+			})
+			return
+		}
+	}
+}
+
+// literal returns the value of w when it contains no expansions, with
+// any quoting removed.
+func literal(w *syntax.Word) (string, bool) {
+	var sb strings.Builder
+	for _, part := range w.Parts {
+		switch part := part.(type) {
+		case *syntax.Lit:
+			sb.WriteString(part.Value)
+		case *syntax.SglQuoted:
+			sb.WriteString(part.Value)
+		case *syntax.DblQuoted:
+			for _, inner := range part.Parts {
+				lit, ok := inner.(*syntax.Lit)
+				if !ok {
+					return "", false
+				}
+				sb.WriteString(lit.Value)
+			}
+		default:
+			return "", false
+		}
+	}
+	return sb.String(), true
+}
+
+// shellQuote quotes s for use as a single shell word.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:=@%+,") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func (r *rewriter) warn(node syntax.Node, format string, args ...interface{}) {
+	r.report.Diagnostics = append(r.report.Diagnostics, Diagnostic{
+		Line:    node.Pos().Line(),
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// guessed records a package whose target name was derived from naming
+// conventions rather than found in the table.
+func (r *rewriter) guessed(name, target string) {
+	guess := name + " -> " + target
+	if !containsString(r.report.Guessed, guess) {
+		r.report.Guessed = append(r.report.Guessed, guess)
+	}
+}
+
+// flagPipeline warns about a pipeline parsing the output of its first
+// command, once for the whole pipeline.
+func (r *rewriter) flagPipeline(stmt *syntax.Stmt, format string, args ...interface{}) {
+	first := pipeline(stmt)[0]
+	if r.flaggedPipelines[first] {
+		return
+	}
+	if r.flaggedPipelines == nil {
+		r.flaggedPipelines = make(map[*syntax.Stmt]bool)
+	}
+	r.flaggedPipelines[first] = true
+	r.warn(stmt, format, args...)
+}
+
+func (r *rewriter) unmapped(name string) {
+	for _, existing := range r.report.Unmapped {
+		if existing == name {
+			return
+		}
+	}
+	r.report.Unmapped = append(r.report.Unmapped, name)
+}
+
+// unavailable records a package the target's release does not provide.
+func (r *rewriter) unavailable(at syntax.Node, name string) {
+	if containsString(r.report.Unavailable, name) {
+		return
+	}
+	r.report.Unavailable = append(r.report.Unavailable, name)
+	r.warn(at, "%s is not available for %s", name, r.target.Name())
+}
+
+// apply writes the edits into a copy of the script. When edits overlap,
+// the one starting first wins, and of those the widest, so a rewrite of a
+// whole command takes precedence over rewrites of its words.
+func (r *rewriter) apply() []byte {
+	return r.applyRange(0, uint(len(r.src)))
+}
+
+// applyRange writes the edits within the byte range from start to end into
+// a copy of that part of the script.
+func (r *rewriter) applyRange(start, end uint) []byte {
+	sort.SliceStable(r.edits, func(i, j int) bool {
+		a, b := r.edits[i], r.edits[j]
+		if a.start != b.start {
+			return a.start < b.start
+		}
+		// Insertions go before any edit replacing text at the same
+		// offset, since they do not overlap it.
+		if (a.start == a.end) != (b.start == b.end) {
+			return a.start == a.end
+		}
+		return a.end > b.end
+	})
+
+	var out bytes.Buffer
+	pos := start
+	for _, e := range r.edits {
+		if e.start < pos || e.end > end {
+			continue
+		}
+		out.Write(r.src[pos:e.start])
+		out.WriteString(e.text)
+		pos = e.end
+	}
+	out.Write(r.src[pos:end])
+	return out.Bytes()
+}
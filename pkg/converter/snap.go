@@ -0,0 +1,143 @@
+package converter
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+//go:embed data/snaps.json
+var snapsJSON []byte
+
+// snapCatalog maps snap names to their Flathub application IDs under the
+// "flatpak" key. An empty ID means the snap is part of snapd itself and
+// needs no replacement. "nixpkgs" holds the attribute of generated Nix
+// modules.
+type snapCatalog struct {
+	Snaps map[string]map[string]string `json:"snaps"`
+}
+
+var snaps snapCatalog
+
+func init() {
+	mustUnmarshal("snaps.json", snapsJSON, &snaps)
+}
+
+const flathubRemote = "flatpak remote-add --if-not-exists flathub https://dl.flathub.org/repo/flathub.flatpakrepo"
+
+// translateSnap returns the Flathub ID of a snap. ok is false when no
+// Flatpak is known.
+func translateSnap(name string) (id string, ok bool) {
+	entry, found := snaps.Snaps[name]
+	if !found {
+		return "", false
+	}
+	id, ok = entry["flatpak"]
+	return id, ok
+}
+
+// snapValueOptions are snap options whose value may be passed as the next
+// argument.
+var snapValueOptions = map[string]bool{
+	"--channel":     true,
+	"--revision":    true,
+	"--cohort":      true,
+	"--name":        true,
+	"--transaction": true,
+}
+
+// rewriteSnap converts snap commands to flatpak. The first install also
+// adds the Flathub remote, which Fedora and Arch Linux do not enable by default.
+func (r *rewriter) rewriteSnap(cmd command) {
+	if len(cmd.args) == 0 {
+		return
+	}
+	sub := cmd.args[0].Lit()
+	var names []*syntax.Word
+	args := cmd.args[1:]
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		lit, _ := r.template(arg)
+		if snapValueOptions[lit] && i+1 < len(args) {
+			i++
+			value, _ := r.template(args[i])
+			lit += " " + value
+		}
+		switch {
+		case lit == "--classic" || lit == "--dangerous" || lit == "--devmode":
+		case strings.HasPrefix(lit, "--channel") || lit == "--edge" || lit == "--beta" || lit == "--candidate":
+			r.warn(arg, "Flathub has no snap channels; %s was dropped", lit)
+		case strings.HasPrefix(lit, "-"):
+			r.warn(arg, "dropped snap option %s", lit)
+		default:
+			names = append(names, arg)
+		}
+	}
+
+	switch sub {
+	case "install", "remove":
+		ids, missing := r.flatpakIDs(names)
+		var parts []string
+		if len(ids) > 0 {
+			verb := "uninstall -y"
+			if sub == "install" {
+				verb = "install -y flathub"
+			}
+			parts = append(parts, r.flatpakCommand(cmd, verb+" "+strings.Join(ids, " ")))
+		}
+		if len(missing) > 0 {
+			msg := fmt.Sprintf("no Flatpak known for snap %s; install it another way", strings.Join(missing, ", "))
+			r.warn(cmd.call, "%s", msg)
+			parts = append(parts, stubText(msg))
+		}
+		if len(parts) == 0 {
+			r.replace(cmd.call, "true")
+			r.warn(cmd.call, "removed snap %s of snapd components, which %s does not need", sub, r.target.Name())
+			return
+		}
+		r.replace(cmd.call, strings.Join(parts, " && "))
+	case "refresh":
+		r.replace(cmd.call, r.flatpakCommand(cmd, "update -y"))
+	case "list":
+		r.replace(cmd.call, r.flatpakCommand(cmd, "list --app"))
+	default:
+		r.stub(cmd, fmt.Sprintf("cannot convert snap %s", r.wordsText(cmd.args)))
+	}
+}
+
+// flatpakIDs returns the Flathub IDs of snap operands and the snaps that
+// have no known Flatpak, which are also added to the report.
+func (r *rewriter) flatpakIDs(names []*syntax.Word) (ids, missing []string) {
+	for _, w := range names {
+		name, _ := r.template(w)
+		id, ok := translateSnap(name)
+		switch {
+		case !ok:
+			missing = append(missing, name)
+			r.unmappedSnap(name)
+		case id != "":
+			ids = append(ids, id)
+		}
+	}
+	return ids, missing
+}
+
+// flatpakCommand returns a flatpak command line keeping the sudo prefix of
+// cmd, preceded by the Flathub remote setup the first time it is needed.
+func (r *rewriter) flatpakCommand(cmd command, args string) string {
+	prefix := r.prefixText(cmd)
+	text := prefix + "flatpak " + args
+	if strings.HasPrefix(args, "install") && !r.flathubAdded {
+		r.flathubAdded = true
+		text = prefix + flathubRemote + " && " + text
+	}
+	return text
+}
+
+func (r *rewriter) unmappedSnap(name string) {
+	if !containsString(r.report.UnmappedSnaps, name) {
+		r.report.UnmappedSnaps = append(r.report.UnmappedSnaps, name)
+	}
+}
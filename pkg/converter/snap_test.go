@@ -0,0 +1,55 @@
+package converter_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSnapConversion tests the translation of snap commands to flatpak
+func TestSnapConversion(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		unmapped []string
+	}{
+		{
+			name:  "Install adds Flathub once",
+			input: "sudo snap install spotify\nsudo snap install code --classic",
+			expected: "sudo flatpak remote-add --if-not-exists flathub https://dl.flathub.org/repo/flathub.flatpakrepo && sudo flatpak install -y flathub com.spotify.Client\n" +
+				"sudo flatpak install -y flathub com.visualstudio.code",
+		},
+		{
+			name:  "Unknown snap",
+			input: "sudo snap install obsidian my-tool",
+			expected: "sudo flatpak remote-add --if-not-exists flathub https://dl.flathub.org/repo/flathub.flatpakrepo && sudo flatpak install -y flathub md.obsidian.Obsidian" +
+				" && { echo 'no Flatpak known for snap my-tool; install it another way' >&2; false; }",
+			unmapped: []string{"my-tool"},
+		},
+		{
+			name:  "Options with a separate value",
+			input: "sudo snap install code --classic --channel edge\nsudo snap remove code --revision 42",
+			expected: "sudo flatpak remote-add --if-not-exists flathub https://dl.flathub.org/repo/flathub.flatpakrepo && sudo flatpak install -y flathub com.visualstudio.code\n" +
+				"sudo flatpak uninstall -y com.visualstudio.code",
+		},
+		{
+			name:     "snapd components",
+			input:    "sudo snap install core22",
+			expected: "true",
+		},
+		{
+			name:     "Remove, refresh and list",
+			input:    "sudo snap remove slack\nsudo snap refresh\nsnap list",
+			expected: "sudo flatpak uninstall -y com.slack.Slack\nsudo flatpak update -y\nflatpak list --app",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converted, report := convertScript(t, tt.input)
+			assert.Equal(t, tt.expected, converted, "The snap command should be translated to flatpak")
+			assert.Equal(t, tt.unmapped, report.UnmappedSnaps, "Unmapped snaps should be reported")
+		})
+	}
+}
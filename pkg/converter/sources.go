@@ -0,0 +1,384 @@
+package converter
+
+import (
+	_ "embed"
+	"fmt"
+	"path"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+//go:embed data/repos.json
+var reposJSON []byte
+
+// repoCatalog maps apt repository URLs to the vendor's RPM repositories.
+// Entries are matched by the longest URL prefix. For each distro key the
+// value is the RPM baseurl, and an empty value means the software is in
+// the distro's own repositories; "<distro>_gpgkey" holds the signing key.
+// Vendors without an entry for the target are treated as unknown. "deb"
+// holds the URI, suite and components of the apt source, which
+// conversions from Fedora write for the vendor's RPM repository.
+type repoCatalog struct {
+	Repos map[string]map[string]string `json:"repos"`
+}
+
+var repos repoCatalog
+
+func init() {
+	mustUnmarshal("repos.json", reposJSON, &repos)
+}
+
+const aptSourcesPath = "/etc/apt/sources.list"
+
+// aptSource is one repository from a sources.list line or a deb822 stanza.
+type aptSource struct {
+	uri      string
+	signedBy string
+}
+
+// lookupRepo returns the catalog entry whose URL is the longest prefix of uri.
+func lookupRepo(uri string) (map[string]string, bool) {
+	uri = strings.TrimSuffix(uri, "/")
+	var best string
+	for prefix := range repos.Repos {
+		if strings.HasPrefix(uri, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return nil, false
+	}
+	return repos.Repos[best], true
+}
+
+// parseAptSources reads one-line "deb [options] uri suite components"
+// entries as well as deb822 stanzas. deb-src entries are skipped.
+func parseAptSources(content string) []aptSource {
+	var sources []aptSource
+	var stanza aptSource
+	isDeb := false
+	flush := func() {
+		if isDeb && stanza.uri != "" {
+			sources = append(sources, stanza)
+		}
+		stanza, isDeb = aptSource{}, false
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "deb "):
+			sources = append(sources, parseAptSourceLine(strings.TrimPrefix(line, "deb ")))
+		case strings.Contains(line, ":"):
+			i := strings.Index(line, ":")
+			value := strings.TrimSpace(line[i+1:])
+			switch strings.ToLower(line[:i]) {
+			case "types":
+				isDeb = containsString(strings.Fields(value), "deb")
+			case "uris":
+				if fields := strings.Fields(value); len(fields) > 0 {
+					stanza.uri = fields[0]
+				}
+			case "signed-by":
+				stanza.signedBy = value
+			}
+		}
+	}
+	flush()
+	return sources
+}
+
+func parseAptSourceLine(line string) aptSource {
+	var src aptSource
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "[") {
+		end := strings.Index(line, "]")
+		if end < 0 {
+			return src
+		}
+		for _, opt := range strings.Fields(line[1:end]) {
+			if strings.HasPrefix(opt, "signed-by=") {
+				src.signedBy = strings.TrimPrefix(opt, "signed-by=")
+			}
+		}
+		line = line[end+1:]
+	}
+	if fields := strings.Fields(line); len(fields) > 0 {
+		src.uri = fields[0]
+	}
+	return src
+}
+
+// fileWrite is a statement writing a configuration file, either through
+// tee or a redirection, with the text it writes.
+type fileWrite struct {
+	target   string
+	content  string
+	node     syntax.Node // the part of the statement to replace
+	heredocs []*syntax.Redirect
+	sudo     bool
+}
+
+// writtenFile recognizes statements writing a file below dir:
+//
+//	echo "..." | sudo tee dir/x
+//	cat <<EOF | sudo tee dir/x
+//	sudo tee dir/x <<EOF
+//	echo "..." > dir/x
+//	cat > dir/x <<EOF
+func (r *rewriter) writtenFile(stmt *syntax.Stmt, dir string) (fileWrite, bool) {
+	w := fileWrite{node: stmt.Cmd}
+	var ok bool
+
+	if pipe, isPipe := stmt.Cmd.(*syntax.BinaryCmd); isPipe && pipe.Op == syntax.Pipe {
+		teeCmd, isCmd := stmtCommand(pipe.Y)
+		if !isCmd {
+			return w, false
+		}
+		if w.target, ok = r.teeTarget(teeCmd, dir); !ok {
+			return w, false
+		}
+		if w.content, w.heredocs, ok = r.producedText(pipe.X); !ok {
+			return w, false
+		}
+		w.sudo = teeCmd.sudo()
+		return w, true
+	}
+
+	cmd, isCmd := stmtCommand(stmt)
+	if !isCmd {
+		return w, false
+	}
+	w.node = &stmtRange{stmt}
+	if w.target, ok = r.teeTarget(cmd, dir); ok {
+		w.sudo = cmd.sudo()
+		w.content, w.heredocs, ok = r.heredocText(stmt)
+	} else if w.target, ok = r.redirectTarget(stmt, dir); ok {
+		w.content, w.heredocs, ok = r.producedText(stmt)
+	}
+	return w, ok
+}
+
+// replaceFileWrite replaces a file write with text and drops its heredocs.
+func (r *rewriter) replaceFileWrite(w fileWrite, text string) {
+	r.replace(w.node, text)
+	for _, redir := range w.heredocs {
+		r.removeHeredoc(redir)
+	}
+}
+
+// rewriteAptSource converts a statement that writes an apt sources file
+// into one that writes an equivalent .repo file, and reports whether stmt
+// was rewritten.
+func (r *rewriter) rewriteAptSource(stmt *syntax.Stmt) bool {
+	w, ok := r.writtenFile(stmt, aptSourcesPath)
+	if !ok {
+		return false
+	}
+	sources := parseAptSources(w.content)
+	if len(sources) == 0 {
+		return false
+	}
+
+	id := strings.TrimSuffix(path.Base(w.target), path.Ext(w.target))
+	if w.target == aptSourcesPath {
+		id = "converted"
+	}
+	replacement, ok := r.repoFileCommand(stmt, id, sources, w.sudo)
+	if !ok {
+		replacement = "true"
+	}
+	r.replaceFileWrite(w, replacement)
+	return true
+}
+
+// repoFileCommand builds the command writing the target's repository file
+// for sources, such as /etc/yum.repos.d/<id>.repo. ok is false when none
+// of the sources are needed on the target. When the target cannot express
+// the repositories, or a vendor has no known repository for it, the
+// command is a failing stub.
+func (r *rewriter) repoFileCommand(at syntax.Node, id string, sources []aptSource, sudo bool) (string, bool) {
+	var repos []Repo
+	var notes, unknown []string
+	for i, src := range sources {
+		vendor, _ := lookupRepo(src.uri)
+		mapped, known := catalogValue(r.target, vendor, "")
+		if !known {
+			unknown = append(unknown, src.uri)
+			continue
+		}
+		if mapped == "" {
+			r.warn(at, "removed apt repository %s: its packages are in the %s repositories", src.uri, r.target.Name())
+			r.useKeyring(src.signedBy)
+			continue
+		}
+		r.useKeyring(src.signedBy)
+		baseurl := mapped
+		gpgkey, _ := catalogValue(r.target, vendor, "_gpgkey")
+		if u, ok := releaseverURL(baseurl); ok {
+			baseurl = u
+			notes = append(notes, fmt.Sprintf("replaced the Ubuntu codename in %s with $releasever", src.uri))
+		}
+		if strings.ContainsAny(baseurl+gpgkey, "`") || strings.Contains(baseurl+gpgkey, "$(") {
+			notes = append(notes, fmt.Sprintf("repository %s uses shell expansions that are not expanded in the .repo file", src.uri))
+		}
+
+		sectionID := id
+		if i > 0 {
+			sectionID = fmt.Sprintf("%s-%d", id, i+1)
+		}
+		repos = append(repos, Repo{ID: sectionID, Name: vendor["name"], BaseURL: baseurl, GPGKey: gpgkey})
+	}
+	// The target cannot read an apt repository's metadata, and an enabled
+	// repository it cannot read breaks every later install.
+	if len(unknown) > 0 {
+		msg := fmt.Sprintf("no %s repository known for %s; install its packages manually", r.target.Name(), strings.Join(unknown, ", "))
+		r.warn(at, "%s", msg)
+		return stubText(msg), true
+	}
+	if len(repos) == 0 {
+		return "", false
+	}
+
+	file, content, ok := r.target.RepoFile(id, repos)
+	if !ok {
+		msg := fmt.Sprintf("no %s repository known for %s; install its packages manually", r.target.Name(), repos[0].BaseURL)
+		r.warn(at, "%s", msg)
+		return stubText(msg), true
+	}
+	for _, note := range notes {
+		r.warn(at, "%s", note)
+	}
+	tee := "tee"
+	if sudo {
+		tee = "sudo tee"
+	}
+	return fmt.Sprintf("echo %s | %s %s > /dev/null", shellQuote(content), tee, file), true
+}
+
+// stmtCommand returns the simple command run by stmt, if it is one.
+func stmtCommand(stmt *syntax.Stmt) (command, bool) {
+	call, ok := stmt.Cmd.(*syntax.CallExpr)
+	if !ok {
+		return command{}, false
+	}
+	return splitCommand(call)
+}
+
+// teeTarget returns the file below dir cmd writes to when it is tee.
+func (r *rewriter) teeTarget(cmd command, dir string) (string, bool) {
+	if cmd.name.Lit() != "tee" {
+		return "", false
+	}
+	for _, arg := range cmd.args {
+		if strings.HasPrefix(arg.Lit(), "-") {
+			continue
+		}
+		target, _ := r.template(arg)
+		return target, strings.HasPrefix(target, dir)
+	}
+	return "", false
+}
+
+// redirectTarget returns the file below dir stmt redirects its output to.
+func (r *rewriter) redirectTarget(stmt *syntax.Stmt, dir string) (string, bool) {
+	for _, redir := range stmt.Redirs {
+		if redir.Op == syntax.RdrOut || redir.Op == syntax.AppOut || redir.Op == syntax.ClbOut {
+			target, _ := r.template(redir.Word)
+			if strings.HasPrefix(target, dir) {
+				return target, true
+			}
+		}
+	}
+	return "", false
+}
+
+// producedText returns the text written by an echo, printf or cat
+// statement, along with the heredocs it reads.
+func (r *rewriter) producedText(stmt *syntax.Stmt) (string, []*syntax.Redirect, bool) {
+	cmd, ok := stmtCommand(stmt)
+	if !ok {
+		return "", nil, false
+	}
+
+	switch cmd.name.Lit() {
+	case "echo", "printf":
+		var parts []string
+		for _, arg := range cmd.args {
+			if strings.HasPrefix(arg.Lit(), "-") {
+				continue
+			}
+			text, _ := r.template(arg)
+			parts = append(parts, text)
+		}
+		return strings.ReplaceAll(strings.Join(parts, " "), `\n`, "\n"), nil, true
+	case "cat":
+		return r.heredocText(stmt)
+	}
+	return "", nil, false
+}
+
+func (r *rewriter) heredocText(stmt *syntax.Stmt) (string, []*syntax.Redirect, bool) {
+	for _, redir := range stmt.Redirs {
+		if (redir.Op == syntax.Hdoc || redir.Op == syntax.DashHdoc) && redir.Hdoc != nil {
+			text, _ := r.template(redir.Hdoc)
+			return text, []*syntax.Redirect{redir}, true
+		}
+	}
+	return "", nil, false
+}
+
+// template returns the value of w with quoting removed and expansions kept
+// as written. dynamic reports whether w contains any expansions.
+func (r *rewriter) template(w *syntax.Word) (value string, dynamic bool) {
+	var sb strings.Builder
+	var add func(parts []syntax.WordPart)
+	add = func(parts []syntax.WordPart) {
+		for _, part := range parts {
+			switch part := part.(type) {
+			case *syntax.Lit:
+				sb.WriteString(part.Value)
+			case *syntax.SglQuoted:
+				sb.WriteString(part.Value)
+			case *syntax.DblQuoted:
+				add(part.Parts)
+			default:
+				sb.WriteString(r.text(part))
+				dynamic = true
+			}
+		}
+	}
+	add(w.Parts)
+	return sb.String(), dynamic
+}
+
+// removeHeredoc deletes the body and closing delimiter of a heredoc.
+func (r *rewriter) removeHeredoc(redir *syntax.Redirect) {
+	start := redir.Hdoc.Pos().Offset()
+	end := redir.Hdoc.End().Offset()
+	if i := strings.IndexByte(string(r.src[end:]), '\n'); i >= 0 {
+		end += uint(i) + 1
+	} else {
+		end = uint(len(r.src))
+	}
+	r.edits = append(r.edits, edit{start: start, end: end})
+}
+
+// stmtRange spans a statement's command and redirections, leaving out any
+// heredoc bodies and the trailing separator.
+type stmtRange struct {
+	*syntax.Stmt
+}
+
+func (s *stmtRange) End() syntax.Pos {
+	end := s.Cmd.End()
+	for _, redir := range s.Redirs {
+		if redir.Word != nil && redir.Word.End().After(end) {
+			end = redir.Word.End()
+		}
+	}
+	return end
+}
@@ -0,0 +1,216 @@
+package converter
+
+import "strings"
+
+// Target is a distribution scripts are converted for. The rewriter parses
+// the Ubuntu commands and looks up the embedded catalogs under the
+// target's ID; the target supplies the commands they become.
+//
+// Methods returning commands take sudo, the privilege prefix such as
+// "sudo ", and place it on the parts of the command that need it.
+type Target interface {
+	// ID is the key of the target in the embedded data files, and Name
+	// its name in messages.
+	ID() string
+	Name() string
+
+	// PackageManager replaces apt and apt-get. Subcommand returns its
+	// spelling of a subcommand of tool, apt or apt-cache, and Option its
+	// spelling of an apt option. ok is false when the package manager has
+	// no equivalent; an empty spelling means none is needed.
+	PackageManager() string
+	Subcommand(tool, sub string) (spelling string, ok bool)
+	Option(flag string) (spelling string, ok bool)
+
+	// Install, Remove and GroupInstall return commands installing or
+	// removing packages, or installing package groups, without prompting.
+	Install(sudo string, pkgs []string) string
+	Remove(sudo string, pkgs []string) string
+	GroupInstall(sudo string, ids []string) string
+
+	// Pin returns the argument installing version of the package name,
+	// an upstream version without Debian revision or epoch.
+	Pin(name, version string) (string, bool)
+
+	// ExtraRepos returns the command enabling optional repositories of the
+	// distribution, such as RPM Fusion or EPEL, named by the "_repos"
+	// entries of the catalogs. ok is false when the target has none of them.
+	ExtraRepos(sudo string, names []string) (string, bool)
+
+	// EnableRepo returns the command enabling, or with remove disabling, a
+	// repository named by the PPA catalog. AddRepoURL returns the command
+	// adding a repository given by URL, and RepoFile the file defining
+	// repos and its content. ok is false when the target cannot express
+	// the repository.
+	EnableRepo(sudo, id string, remove bool) (string, bool)
+	AddRepoURL(sudo, url string) (string, bool)
+	RepoFile(id string, repos []Repo) (path, content string, ok bool)
+
+	// ImportKey returns the command trusting the signing key at source, a
+	// URL or file, ListKeys one listing the trusted keys and DeleteKey one
+	// removing the key with the given ID.
+	ImportKey(sudo, source string) string
+	ListKeys() string
+	DeleteKey(sudo, id string) string
+
+	// Arch returns the target's name for a Debian architecture.
+	Arch(debian string) (string, bool)
+
+	// DevPackage guesses the name of a Debian -dev package missing from
+	// the catalog from the target's naming conventions.
+	DevPackage(name string) (string, bool)
+}
+
+// Repo is a package repository converted from an apt source.
+type Repo struct {
+	ID      string
+	Name    string
+	BaseURL string
+	GPGKey  string
+}
+
+// rpmTarget is implemented by targets whose packages are RPMs. They get
+// the conversion of dpkg queries to rpm, and of ufw to firewalld, which
+// they ship instead.
+type rpmTarget interface {
+	Target
+	rpm()
+}
+
+// releaseTarget is implemented by rpm targets with numbered releases.
+// releaseMacro is the rpm macro holding the release number, such as
+// %fedora. Only they get codenames converted to release numbers.
+type releaseTarget interface {
+	rpmTarget
+	releaseMacro() string
+}
+
+// dnfTarget is implemented by targets managing packages with dnf. Only
+// they get the conversions of apt-mark, unattended-upgrades and
+// update-alternatives. grammar is the spelling of dnf's commands.
+type dnfTarget interface {
+	releaseTarget
+	dnf()
+	grammar() *dnfGrammar
+}
+
+// familyTarget is implemented by targets sharing catalog entries with the
+// other releases of a family, such as "el" for el8 and el9. Entries under
+// the target's own ID take precedence. "<family>_releases" lists the
+// releases providing a package when not all of them do.
+type familyTarget interface {
+	Target
+	family() (name, release string)
+}
+
+// impliedOptionTarget is implemented by targets whose package manager
+// always behaves as if given some apt options, such as -y. Implied reports
+// whether the apt option flag is one of them, which is dropped without a
+// warning.
+type impliedOptionTarget interface {
+	Target
+	Implied(flag string) bool
+}
+
+// installNoteTarget is implemented by targets whose package installs need
+// an explanation, such as a required reboot. It is reported once per
+// script.
+type installNoteTarget interface {
+	Target
+	installNote() string
+}
+
+// batchInstallTarget is implemented by targets whose installs of system
+// packages are merged into one command per script, as each is a slow
+// transaction that takes effect only after a reboot.
+type batchInstallTarget interface {
+	Target
+	batchInstalls()
+}
+
+// osReleaseTarget is implemented by targets whose /etc/os-release ID is
+// not their target ID. note explains how far the ID identifies the target,
+// if it also identifies other systems.
+type osReleaseTarget interface {
+	Target
+	osRelease() (id, note string)
+}
+
+// globalOptionTarget is implemented by targets whose package manager only
+// accepts some options before the subcommand. GlobalOption reports whether
+// an option, in the target's spelling, is one of them.
+type globalOptionTarget interface {
+	Target
+	GlobalOption(spelling string) bool
+}
+
+// Targets returns the distributions scripts can be converted for.
+func Targets() []Target {
+	return []Target{Fedora, FedoraDNF5, FedoraAtomic, ArchLinux, OpenSUSE, EL9, EL8}
+}
+
+// LookupTarget returns the target with the given ID.
+func LookupTarget(id string) (Target, bool) {
+	for _, t := range Targets() {
+		if t.ID() == id {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// catalogValue returns the value of a data file entry for t, looked up
+// under the target's ID and then its family, with suffix appended to the
+// key.
+func catalogValue(t Target, entry map[string]string, suffix string) (string, bool) {
+	if value, ok := entry[t.ID()+suffix]; ok {
+		return value, true
+	}
+	if f, ok := t.(familyTarget); ok {
+		name, _ := f.family()
+		value, ok := entry[name+suffix]
+		return value, ok
+	}
+	return "", false
+}
+
+// releaseProvides reports whether the release of t provides the package
+// of a catalog entry.
+func releaseProvides(t Target, entry map[string]string) bool {
+	f, ok := t.(familyTarget)
+	if !ok {
+		return true
+	}
+	name, release := f.family()
+	releases, ok := entry[name+"_releases"]
+	return !ok || containsString(strings.Fields(releases), release)
+}
+
+// toolPrefixes are the catalog package name prefixes that name the tool
+// installing the package instead of the package manager.
+var toolPrefixes = []string{aurPrefix, flatpakPrefix, toolboxPrefix}
+
+// toolPrefix returns the tool prefix of a catalog package name, if any.
+// Paths such as ./zoom.rpm have none.
+func toolPrefix(name string) (string, bool) {
+	for _, prefix := range toolPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+// separatePackage reports whether a catalog package name is prefixed with
+// the tool installing it, such as aur/ or flatpak/. Such packages are
+// installed by a command of their own.
+func separatePackage(name string) bool {
+	_, ok := toolPrefix(name)
+	return ok
+}
+
+// packageName returns a catalog package name without its tool prefix.
+func packageName(name string) string {
+	prefix, _ := toolPrefix(name)
+	return strings.TrimPrefix(name, prefix)
+}
@@ -0,0 +1,10 @@
+#!/bin/bash
+# Keep the kernel and Docker from being upgraded behind our back
+sudo dnf versionlock add kernel docker-ce
+sudo dnf versionlock add gcc gcc-c++ make
+
+dnf versionlock list
+dnf repoquery --userinstalled --queryformat '%{name}\n' > manual-packages.txt
+
+sudo dnf versionlock delete docker-ce
+sudo dnf mark dependency openssl-devel
@@ -0,0 +1,10 @@
+#!/bin/bash
+# Keep the kernel and Docker from being upgraded behind our back
+sudo apt-mark hold linux-image-generic docker-ce
+sudo apt-mark hold build-essential
+
+apt-mark showhold
+apt-mark showmanual > manual-packages.txt
+
+sudo apt-mark unhold docker-ce
+sudo apt-mark auto libssl-dev
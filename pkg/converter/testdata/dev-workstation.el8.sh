@@ -0,0 +1,35 @@
+#!/bin/bash
+# Set up a development workstation
+set -e
+
+case "$(sed -n 's/^ID=//p' /etc/os-release | tr -d '"')" in
+  rhel) ;;
+  *) echo "unsupported distribution" >&2; exit 1 ;;
+esac
+
+sudo yum update && sudo yum upgrade -y
+sudo yum groupinstall -y development && sudo yum config-manager --set-enabled powertools && sudo yum install -y epel-release && sudo yum install -y git curl fd-find ripgrep openssl-devel libyaml-devel zlib-devel
+sudo yum install -y --setopt=install_weak_deps=False python3-pip
+
+# Visual Studio Code from Microsoft's repository
+true
+true
+echo '[vscode]
+name=Visual Studio Code
+baseurl=https://packages.microsoft.com/yumrepos/vscode
+enabled=1
+gpgcheck=1
+gpgkey=https://packages.microsoft.com/keys/microsoft.asc' | sudo tee /etc/yum.repos.d/vscode.repo > /dev/null
+true
+sudo yum update
+sudo yum install -y code
+
+{ echo 'no COPR equivalent known for ppa:lazygit-team/release; enable a repository for it on Enterprise Linux 8 manually' >&2; false; }
+sudo yum install -y lazygit
+
+if ! rpm -qa 'docker*' | grep -q .; then
+  sudo yum install -y docker-ce docker-ce-cli containerd.io
+fi
+
+sudo firewall-cmd --permanent --add-port=22/tcp && sudo firewall-cmd --reload
+sudo yum autoremove -y
@@ -0,0 +1,35 @@
+#!/bin/bash
+# Set up a development workstation
+set -e
+
+case "$(sed -n 's/^ID=//p' /etc/os-release | tr -d '"')" in
+  fedora) ;;
+  *) echo "unsupported distribution" >&2; exit 1 ;;
+esac
+
+sudo rpm-ostree refresh-md && sudo rpm-ostree upgrade
+{ toolbox run true > /dev/null 2>&1 || toolbox create -y; } && toolbox run sudo dnf group install -y development-tools c-development && { toolbox run true > /dev/null 2>&1 || toolbox create -y; } && toolbox run sudo dnf install -y openssl-devel libyaml-devel zlib-devel
+{ toolbox run true > /dev/null 2>&1 || toolbox create -y; } && toolbox run sudo dnf install -y python3-pip pipx
+
+# Visual Studio Code from Microsoft's repository
+true
+true
+echo '[vscode]
+name=Visual Studio Code
+baseurl=https://packages.microsoft.com/yumrepos/vscode
+enabled=1
+gpgcheck=1
+gpgkey=https://packages.microsoft.com/keys/microsoft.asc' | sudo tee /etc/yum.repos.d/vscode.repo > /dev/null
+true
+sudo rpm-ostree refresh-md
+sudo flatpak remote-add --if-not-exists flathub https://dl.flathub.org/repo/flathub.flatpakrepo && sudo flatpak install -y flathub com.visualstudio.code
+
+sudo curl -fsSL --output-dir /etc/yum.repos.d -O https://copr.fedorainfracloud.org/coprs/atim/lazygit/repo/fedora-$(rpm -E %fedora)/atim-lazygit-fedora-$(rpm -E %fedora).repo
+sudo rpm-ostree install --idempotent git curl fd-find ripgrep lazygit
+
+if ! rpm -qa 'docker*' | grep -q .; then
+  sudo rpm-ostree install --idempotent docker-ce docker-ce-cli containerd.io
+fi
+
+sudo firewall-cmd --permanent --add-port=22/tcp && sudo firewall-cmd --reload
+true
@@ -0,0 +1,37 @@
+#!/bin/bash
+# Set up a development workstation
+set -e
+
+case "$(sed -n 's/^ID=//p' /etc/os-release | tr -d '"')" in
+  opensuse-tumbleweed) ;;
+  *) echo "unsupported distribution" >&2; exit 1 ;;
+esac
+
+sudo zypper --gpg-auto-import-keys refresh && sudo zypper --non-interactive dist-upgrade
+sudo zypper --non-interactive install -t pattern devel_basis && sudo zypper --non-interactive install git curl fd ripgrep libopenssl-devel libyaml-devel zlib-devel
+sudo zypper --non-interactive install --no-recommends python3-pip python3-pipx
+
+# Visual Studio Code from Microsoft's repository
+true
+true
+echo '[vscode]
+name=Visual Studio Code
+baseurl=https://packages.microsoft.com/yumrepos/vscode
+enabled=1
+autorefresh=1
+type=rpm-md
+gpgcheck=1
+gpgkey=https://packages.microsoft.com/keys/microsoft.asc' | sudo tee /etc/zypp/repos.d/vscode.repo > /dev/null
+true
+sudo zypper --gpg-auto-import-keys refresh
+sudo zypper --non-interactive install code
+
+true
+sudo zypper --non-interactive install lazygit
+
+if ! rpm -qa 'docker*' | grep -q .; then
+  sudo zypper --non-interactive install docker containerd
+fi
+
+sudo firewall-cmd --permanent --add-port=22/tcp && sudo firewall-cmd --reload
+true
@@ -0,0 +1,30 @@
+#!/bin/bash
+# Set up a development workstation
+set -e
+
+case "$(lsb_release -is)" in
+  Ubuntu|Debian) ;;
+  *) echo "unsupported distribution" >&2; exit 1 ;;
+esac
+
+sudo apt update && sudo apt upgrade -y
+sudo apt install -y build-essential git curl fd-find ripgrep libssl-dev libyaml-dev zlib1g-dev
+sudo apt-get install -y --no-install-recommends python3-pip pipx
+
+# Visual Studio Code from Microsoft's repository
+wget -qO- https://packages.microsoft.com/keys/microsoft.asc | gpg --dearmor > packages.microsoft.gpg
+sudo install -D -o root -g root -m 644 packages.microsoft.gpg /etc/apt/keyrings/packages.microsoft.gpg
+echo "deb [arch=amd64 signed-by=/etc/apt/keyrings/packages.microsoft.gpg] https://packages.microsoft.com/repos/code stable main" | sudo tee /etc/apt/sources.list.d/vscode.list > /dev/null
+rm -f packages.microsoft.gpg
+sudo apt update
+sudo apt install -y code
+
+sudo add-apt-repository -y ppa:lazygit-team/release
+sudo apt install -y lazygit
+
+if ! dpkg -l | grep -q '^ii  docker'; then
+  sudo apt install -y docker-ce docker-ce-cli containerd.io
+fi
+
+sudo ufw allow 22/tcp
+sudo apt autoremove -y
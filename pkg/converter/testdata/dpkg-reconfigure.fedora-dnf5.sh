@@ -0,0 +1,3 @@
+#!/bin/bash
+sudo dnf install -y dnf5-plugin-automatic
+sudo cp --update=none /usr/share/dnf5/dnf5-plugins/automatic.conf /etc/dnf/automatic.conf && sudo sed -i -e 's/^apply_updates = .*/apply_updates = yes/' /etc/dnf/automatic.conf && sudo systemctl enable --now dnf5-automatic.timer
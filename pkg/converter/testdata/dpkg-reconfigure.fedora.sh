@@ -0,0 +1,3 @@
+#!/bin/bash
+sudo dnf install -y dnf-automatic
+sudo sed -i -e 's/^apply_updates = .*/apply_updates = yes/' /etc/dnf/automatic.conf && sudo systemctl enable --now dnf-automatic.timer
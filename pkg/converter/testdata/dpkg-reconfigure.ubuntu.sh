@@ -0,0 +1,3 @@
+#!/bin/bash
+sudo apt-get install -y unattended-upgrades
+sudo dpkg-reconfigure -plow unattended-upgrades
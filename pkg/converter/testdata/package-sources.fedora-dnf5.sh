@@ -0,0 +1,18 @@
+#!/bin/bash
+# Add third-party sources and inspect the available versions
+set -e
+
+. /etc/os-release
+if [ "$ID" != fedora ]; then
+  echo "unsupported distribution: $ID" >&2
+  exit 1
+fi
+
+sudo dnf install -y dnf5-plugins
+sudo dnf config-manager addrepo --from-repofile=https://download.docker.com/linux/fedora/docker-ce.repo
+sudo dnf copr enable -y atim/lazygit
+sudo dnf install -y --no-gpgchecks lazygit
+
+dnf repoquery --showduplicates --queryformat '%{name} | %{evr} | %{repoid}\n' docker-ce
+dnf repoquery --queryformat '%{name}\n' | grep -c docker
+sudo dnf mark user lazygit
@@ -0,0 +1,18 @@
+#!/bin/bash
+# Add third-party sources and inspect the available versions
+set -e
+
+. /etc/os-release
+if [ "$ID" != fedora ]; then
+  echo "unsupported distribution: $ID" >&2
+  exit 1
+fi
+
+sudo dnf install -y dnf-plugins-core
+sudo dnf config-manager --add-repo https://download.docker.com/linux/fedora/docker-ce.repo
+sudo dnf copr enable -y atim/lazygit
+sudo dnf install -y --nogpgcheck lazygit
+
+dnf repoquery --showduplicates --queryformat '%{name} | %{evr} | %{repoid}' docker-ce
+dnf repoquery --queryformat '%{name}' | grep -c docker
+sudo dnf mark install lazygit
@@ -0,0 +1,18 @@
+#!/bin/bash
+# Add third-party sources and inspect the available versions
+set -e
+
+. /etc/os-release
+if [ "$ID" != ubuntu ]; then
+  echo "unsupported distribution: $ID" >&2
+  exit 1
+fi
+
+sudo apt install -y software-properties-common
+sudo add-apt-repository -y https://download.docker.com/linux/fedora/docker-ce.repo
+sudo add-apt-repository -y ppa:lazygit-team/release
+sudo apt install -y --allow-unauthenticated lazygit
+
+apt-cache madison docker-ce
+apt-cache pkgnames | grep -c docker
+sudo apt-mark manual lazygit
@@ -0,0 +1,38 @@
+#!/bin/bash
+# Sets up a Fedora development workstation
+set -e
+
+sudo dnf upgrade -y --refresh
+sudo dnf install -y dnf-plugins-core git curl gnome-tweaks openssl-devel libyaml-devel
+sudo dnf group install -y development-tools c-development
+
+# RPM Fusion for codecs
+sudo dnf install -y https://mirrors.rpmfusion.org/free/fedora/rpmfusion-free-release-$(rpm -E %fedora).noarch.rpm
+sudo dnf install -y @multimedia
+
+# lazygit from COPR
+sudo dnf copr enable -y atim/lazygit
+sudo dnf install -y lazygit
+
+# Docker CE
+sudo rpm --import https://download.docker.com/linux/fedora/gpg
+sudo tee /etc/yum.repos.d/docker-ce.repo <<REPO
+[docker-ce-stable]
+name=Docker CE Stable - \$basearch
+baseurl=https://download.docker.com/linux/fedora/\$releasever/\$basearch/stable
+enabled=1
+gpgcheck=1
+gpgkey=https://download.docker.com/linux/fedora/gpg
+REPO
+sudo dnf install -y docker-ce docker-ce-cli containerd.io
+
+# GitHub CLI
+sudo dnf config-manager --add-repo https://cli.github.com/packages/rpm/gh-cli.repo
+sudo dnf install -y gh
+
+if ! rpm -q --quiet code; then
+  echo "Fedora $(rpm -E %fedora): VS Code is not installed"
+fi
+
+sudo dnf autoremove -y
+sudo dnf clean all
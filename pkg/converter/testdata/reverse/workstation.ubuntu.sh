@@ -0,0 +1,31 @@
+#!/bin/bash
+# Sets up a Fedora development workstation
+set -e
+
+sudo apt-get update && sudo apt-get upgrade -y
+sudo apt-get install -y software-properties-common git curl gnome-tweaks libssl-dev libyaml-dev
+sudo apt-get install -y build-essential
+
+# RPM Fusion for codecs
+true
+sudo apt-get install -y ubuntu-restricted-extras
+
+# lazygit from COPR
+sudo add-apt-repository -y ppa:lazygit-team/release
+sudo apt-get install -y lazygit
+
+# Docker CE
+true
+curl -fsSL https://download.docker.com/linux/fedora/gpg | sudo gpg --dearmor --yes -o /etc/apt/keyrings/docker-ce.gpg && echo "deb [arch=$(dpkg --print-architecture) signed-by=/etc/apt/keyrings/docker-ce.gpg] https://download.docker.com/linux/ubuntu $(lsb_release -cs) stable" | sudo tee /etc/apt/sources.list.d/docker-ce.list > /dev/null
+sudo apt-get install -y docker-ce docker-ce-cli containerd.io
+
+# GitHub CLI
+curl -fsSL https://cli.github.com/packages/rpm/gh-cli.repo.asc | sudo gpg --dearmor --yes -o /etc/apt/keyrings/gh-cli.gpg && echo "deb [arch=$(dpkg --print-architecture) signed-by=/etc/apt/keyrings/gh-cli.gpg] https://cli.github.com/packages stable main" | sudo tee /etc/apt/sources.list.d/gh-cli.list > /dev/null
+sudo apt-get install -y gh
+
+if ! dpkg -s code > /dev/null 2>&1; then
+  echo "Fedora $(lsb_release -rs): VS Code is not installed"
+fi
+
+sudo apt-get autoremove -y
+sudo apt-get clean
@@ -0,0 +1,23 @@
+#!/bin/bash
+set -e
+
+case "$(lsb_release -is)" in
+  Ubuntu|Debian) ;;
+  *) exit 0 ;;
+esac
+
+sudo apt install -y unattended-upgrades apt-listchanges
+
+cat <<EOT | sudo tee /etc/apt/apt.conf.d/20auto-upgrades
+APT::Periodic::Update-Package-Lists "1";
+APT::Periodic::Unattended-Upgrade "1";
+EOT
+
+sudo tee /etc/apt/apt.conf.d/52unattended-upgrades-local > /dev/null <<'EOT'
+Unattended-Upgrade::Allowed-Origins {
+	"${distro_id}:${distro_codename}-security";
+};
+Unattended-Upgrade::Automatic-Reboot "true";
+EOT
+
+sudo systemctl enable --now unattended-upgrades
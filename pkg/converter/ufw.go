@@ -0,0 +1,302 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ufwServices maps ufw application profiles and service names to firewalld
+// services.
+var ufwServices = map[string][]string{
+	"ssh":           {"ssh"},
+	"openssh":       {"ssh"},
+	"http":          {"http"},
+	"https":         {"https"},
+	"www":           {"http"},
+	"nginx full":    {"http", "https"},
+	"nginx http":    {"http"},
+	"nginx https":   {"https"},
+	"apache":        {"http"},
+	"apache full":   {"http", "https"},
+	"apache secure": {"https"},
+	"www full":      {"http", "https"},
+	"www secure":    {"https"},
+	"samba":         {"samba"},
+	"cups":          {"ipp"},
+	"dns":           {"dns"},
+	"domain":        {"dns"},
+	"smtp":          {"smtp"},
+	"imap":          {"imap"},
+	"imaps":         {"imaps"},
+	"pop3":          {"pop3"},
+	"pop3s":         {"pop3s"},
+	"mysql":         {"mysql"},
+	"postgresql":    {"postgresql"},
+	"ntp":           {"ntp"},
+	"nfs":           {"nfs"},
+}
+
+var ufwPortPattern = regexp.MustCompile(`^([0-9]+(?:[:,][0-9]+)*)(?:/(tcp|udp))?$`)
+
+// richActions maps ufw rule actions to firewalld rich rule actions.
+var richActions = map[string]string{
+	"allow":  "accept",
+	"deny":   "drop",
+	"reject": "reject",
+}
+
+// ufwRule is an allow, deny or reject rule in either of ufw's grammars:
+// "ufw allow 22/tcp" or "ufw allow from 10.0.0.0/8 to any port 22 proto tcp".
+type ufwRule struct {
+	action   string
+	remove   bool
+	from     string
+	to       string
+	ports    []string
+	proto    string
+	services []string
+}
+
+// rewriteUfw converts ufw commands to firewall-cmd and systemctl. Rules go
+// to the permanent configuration, followed by a reload to apply them.
+// Targets without firewalld keep ufw.
+func (r *rewriter) rewriteUfw(cmd command) {
+	if !r.rpm() {
+		return
+	}
+	var args []string
+	for _, arg := range cmd.args {
+		value, _ := r.template(arg)
+		if value != "--force" {
+			args = append(args, value)
+		}
+	}
+	if len(args) == 0 {
+		return
+	}
+
+	prefix := r.prefixText(cmd)
+	switch args[0] {
+	case "enable":
+		r.replace(cmd.call, prefix+"systemctl enable --now firewalld")
+	case "disable":
+		r.replace(cmd.call, prefix+"systemctl disable --now firewalld")
+	case "reload":
+		r.replace(cmd.call, prefix+"firewall-cmd --reload")
+	case "status":
+		r.replace(cmd.call, prefix+"firewall-cmd --list-all")
+	case "app":
+		if len(args) > 1 && args[1] == "list" {
+			r.replace(cmd.call, prefix+"firewall-cmd --get-services")
+			return
+		}
+		r.unexpressedRule(cmd, "application profiles are firewalld services")
+	case "logging":
+		level := "all"
+		if len(args) > 1 && args[1] == "off" {
+			level = "off"
+		}
+		r.replace(cmd.call, prefix+"firewall-cmd --set-log-denied="+level)
+	case "default":
+		r.rewriteUfwDefault(cmd, args[1:])
+	case "allow", "deny", "reject", "limit", "delete", "insert", "prepend":
+		rule, reason := parseUfwRule(args)
+		if reason != "" {
+			r.unexpressedRule(cmd, reason)
+			return
+		}
+		if args[0] == "insert" || args[0] == "prepend" {
+			r.warn(cmd.call, "firewalld rules are not ordered; the rule position was dropped")
+		}
+		r.replace(cmd.call, fmt.Sprintf("%sfirewall-cmd --permanent %s && %sfirewall-cmd --reload",
+			prefix, strings.Join(rule.options(), " "), prefix))
+	default:
+		r.unexpressedRule(cmd, "no firewalld equivalent")
+	}
+}
+
+// rewriteUfwDefault converts default policies. Incoming traffic is governed
+// by the default zone; firewalld allows outgoing and blocks routed traffic.
+func (r *rewriter) rewriteUfwDefault(cmd command, args []string) {
+	if len(args) == 0 {
+		r.unexpressedRule(cmd, "missing policy")
+		return
+	}
+	direction := "incoming"
+	if len(args) > 1 {
+		direction = args[1]
+	}
+	policy := args[0]
+	prefix := r.prefixText(cmd)
+	switch {
+	case direction == "incoming" && (policy == "deny" || policy == "reject"):
+		r.replace(cmd.call, prefix+"firewall-cmd --set-default-zone=public")
+	case direction == "incoming" && policy == "allow":
+		r.replace(cmd.call, prefix+"firewall-cmd --set-default-zone=trusted")
+	case direction == "outgoing" && policy == "allow", direction == "routed" && policy != "allow":
+		r.replace(cmd.call, "true")
+	default:
+		r.unexpressedRule(cmd, fmt.Sprintf("firewalld has no default %s policy for %s traffic", policy, direction))
+	}
+}
+
+// parseUfwRule parses a rule command. reason explains why a rule cannot be
+// expressed in firewalld, and is empty on success.
+func parseUfwRule(args []string) (rule ufwRule, reason string) {
+	switch args[0] {
+	case "delete":
+		rule.remove = true
+		args = args[1:]
+	case "insert":
+		if len(args) < 2 {
+			return rule, "missing rule number"
+		}
+		args = args[2:]
+	case "prepend":
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return rule, "missing rule"
+	}
+	rule.action = args[0]
+	switch rule.action {
+	case "allow", "deny", "reject":
+	case "limit":
+		return rule, "firewalld has no connection rate limiting like ufw limit"
+	default:
+		return rule, "rules can only be deleted by their specification, not by number"
+	}
+
+	rule.from, rule.to = "any", "any"
+	var words []string
+	for _, arg := range args[1:] {
+		switch arg {
+		case "in", "log", "log-all":
+		case "out":
+			return rule, "firewalld does not filter outgoing traffic"
+		default:
+			words = append(words, arg)
+		}
+	}
+
+	var ports string
+	if len(words)%2 == 1 {
+		if m := ufwPortPattern.FindStringSubmatch(words[0]); m != nil {
+			ports, rule.proto = m[1], m[2]
+		} else if services, ok := ufwServices[strings.ToLower(words[0])]; ok {
+			rule.services = services
+		} else {
+			return rule, fmt.Sprintf("no firewalld service known for %s", words[0])
+		}
+		words = words[1:]
+	}
+	for i := 0; i < len(words); i += 2 {
+		key, value := words[i], words[i+1]
+		switch key {
+		case "from":
+			rule.from = value
+		case "to":
+			rule.to = value
+		case "port":
+			ports = value
+		case "proto":
+			rule.proto = value
+		case "app":
+			services, ok := ufwServices[strings.ToLower(value)]
+			if !ok {
+				return rule, fmt.Sprintf("no firewalld service known for %s", value)
+			}
+			rule.services = services
+		case "comment":
+		case "on":
+			return rule, "interface rules need a firewalld zone for the interface"
+		default:
+			return rule, fmt.Sprintf("unsupported ufw keyword %s", key)
+		}
+	}
+
+	if rule.proto != "" && rule.proto != "tcp" && rule.proto != "udp" {
+		return rule, fmt.Sprintf("firewalld ports cannot use protocol %s", rule.proto)
+	}
+	for _, p := range strings.Split(ports, ",") {
+		if p != "" {
+			rule.ports = append(rule.ports, strings.Replace(p, ":", "-", 1))
+		}
+	}
+	if len(rule.ports) == 0 && len(rule.services) == 0 && rule.from == "any" && rule.to == "any" {
+		return rule, "the rule matches no traffic"
+	}
+	return rule, ""
+}
+
+// options returns the firewall-cmd options adding or removing the rule.
+// Plain allow rules become ports and services; rules with addresses or
+// deny and reject actions need rich rules.
+func (rule ufwRule) options() []string {
+	verb := "--add-"
+	if rule.remove {
+		verb = "--remove-"
+	}
+	protos := []string{"tcp", "udp"}
+	if rule.proto != "" {
+		protos = []string{rule.proto}
+	}
+
+	var opts []string
+	if rule.action == "allow" && rule.from == "any" && rule.to == "any" {
+		for _, port := range rule.ports {
+			for _, proto := range protos {
+				opts = append(opts, verb+"port="+port+"/"+proto)
+			}
+		}
+		for _, service := range rule.services {
+			opts = append(opts, verb+"service="+service)
+		}
+		return opts
+	}
+
+	var base []string
+	if rule.from != "any" || rule.to != "any" {
+		family := "ipv4"
+		if strings.Contains(rule.from+rule.to, ":") {
+			family = "ipv6"
+		}
+		base = append(base, fmt.Sprintf("family=%q", family))
+	}
+	if rule.from != "any" {
+		base = append(base, fmt.Sprintf("source address=%q", rule.from))
+	}
+	if rule.to != "any" {
+		base = append(base, fmt.Sprintf("destination address=%q", rule.to))
+	}
+	var matches []string
+	for _, port := range rule.ports {
+		for _, proto := range protos {
+			matches = append(matches, fmt.Sprintf("port port=%q protocol=%q", port, proto))
+		}
+	}
+	for _, service := range rule.services {
+		matches = append(matches, fmt.Sprintf("service name=%q", service))
+	}
+	if len(matches) == 0 {
+		matches = []string{""}
+	}
+	for _, match := range matches {
+		parts := append([]string{"rule"}, base...)
+		if match != "" {
+			parts = append(parts, match)
+		}
+		parts = append(parts, richActions[rule.action])
+		opts = append(opts, verb+"rich-rule="+shellQuote(strings.Join(parts, " ")))
+	}
+	return opts
+}
+
+// unexpressedRule replaces a ufw command with no firewalld equivalent by a
+// failing stub and lists it in the report.
+func (r *rewriter) unexpressedRule(cmd command, reason string) {
+	rule := "ufw " + r.wordsText(cmd.args)
+	r.report.FirewallRules = append(r.report.FirewallRules, rule)
+	r.stub(cmd, fmt.Sprintf("cannot express %s in firewalld: %s", rule, reason))
+}
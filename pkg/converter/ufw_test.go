@@ -0,0 +1,70 @@
+package converter_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestUfwRules tests the translation of ufw to firewalld
+func TestUfwRules(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    string
+		unexpressed []string
+	}{
+		{
+			name:     "Port rule",
+			input:    "sudo ufw allow 22/tcp",
+			expected: "sudo firewall-cmd --permanent --add-port=22/tcp && sudo firewall-cmd --reload",
+		},
+		{
+			name:     "Port range without protocol",
+			input:    "sudo ufw allow 1714:1764",
+			expected: "sudo firewall-cmd --permanent --add-port=1714-1764/tcp --add-port=1714-1764/udp && sudo firewall-cmd --reload",
+		},
+		{
+			name:     "Application profile",
+			input:    "sudo ufw allow 'Nginx Full'",
+			expected: "sudo firewall-cmd --permanent --add-service=http --add-service=https && sudo firewall-cmd --reload",
+		},
+		{
+			name:  "Source address",
+			input: "sudo ufw allow from 192.168.1.0/24 to any port 22 proto tcp",
+			expected: "sudo firewall-cmd --permanent --add-rich-rule='rule family=\"ipv4\" source address=\"192.168.1.0/24\" port port=\"22\" protocol=\"tcp\" accept'" +
+				" && sudo firewall-cmd --reload",
+		},
+		{
+			name:     "Deleting a rule",
+			input:    "sudo ufw delete allow ssh",
+			expected: "sudo firewall-cmd --permanent --remove-service=ssh && sudo firewall-cmd --reload",
+		},
+		{
+			name:     "Deny rule",
+			input:    "sudo ufw deny 23/tcp",
+			expected: "sudo firewall-cmd --permanent --add-rich-rule='rule port port=\"23\" protocol=\"tcp\" drop' && sudo firewall-cmd --reload",
+		},
+		{
+			name:  "Defaults and enabling",
+			input: "sudo ufw default deny incoming\nsudo ufw default allow outgoing\nsudo ufw --force enable\nsudo ufw status verbose",
+			expected: "sudo firewall-cmd --set-default-zone=public\ntrue\n" +
+				"sudo systemctl enable --now firewalld\nsudo firewall-cmd --list-all",
+		},
+		{
+			name:  "Rules that cannot be expressed",
+			input: "sudo ufw limit ssh\nsudo ufw allow in on docker0 to any port 80",
+			expected: "{ echo 'cannot express ufw limit ssh in firewalld: firewalld has no connection rate limiting like ufw limit' >&2; false; }\n" +
+				"{ echo 'cannot express ufw allow in on docker0 to any port 80 in firewalld: interface rules need a firewalld zone for the interface' >&2; false; }",
+			unexpressed: []string{"ufw limit ssh", "ufw allow in on docker0 to any port 80"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converted, report := convertScript(t, tt.input)
+			assert.Equal(t, tt.expected, converted, "ufw should be translated to firewall-cmd")
+			assert.Equal(t, tt.unexpressed, report.FirewallRules, "Rules with no firewalld equivalent should be listed")
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+const (
+	aptConfDir         = "/etc/apt/apt.conf.d/"
+	dnfAutomaticConf   = "/etc/dnf/automatic.conf"
+	unattendedUpgrades = "unattended-upgrades"
+)
+
+var (
+	periodicPattern   = regexp.MustCompile(`APT::Periodic::(Unattended-Upgrade|Update-Package-Lists)\s+"([0-9]+)"`)
+	autoRebootPattern = regexp.MustCompile(`Unattended-Upgrade::Automatic-Reboot\s+"(true|false)"`)
+)
+
+// dnfAutomaticSettings lists automatic.conf changes as sed expressions.
+type dnfAutomaticSettings []string
+
+func (s *dnfAutomaticSettings) set(key, value string) {
+	*s = append(*s, fmt.Sprintf("-e 's/^%s = .*/%s = %s/'", key, key, value))
+}
+
+// rewriteUnattendedConfig converts a statement writing unattended-upgrades
+// settings to /etc/apt/apt.conf.d into a dnf-automatic configuration, and
+// reports whether stmt was rewritten.
+func (r *rewriter) rewriteUnattendedConfig(stmt *syntax.Stmt) bool {
+	w, ok := r.writtenFile(stmt, aptConfDir)
+	if !ok || !strings.Contains(w.content, "Unattended-Upgrade") && !strings.Contains(w.content, "APT::Periodic") {
+		return false
+	}
+	if !r.dnf() {
+		r.replaceFileWrite(w, "true")
+		r.warn(stmt, "removed %s: %s has no automatic updates to configure", w.target, r.target.Name())
+		return true
+	}
+	prefix := ""
+	if w.sudo {
+		prefix = "sudo "
+	}
+
+	// enable is nil when the file does not switch automatic updates on or off.
+	var enable *bool
+	var settings dnfAutomaticSettings
+	for _, m := range periodicPattern.FindAllStringSubmatch(w.content, -1) {
+		on := m[2] != "0"
+		switch {
+		case m[1] == "Unattended-Upgrade":
+			enable = &on
+			if on {
+				settings.set("apply_updates", "yes")
+			}
+		case enable == nil && on:
+			enable = &on
+		}
+	}
+	if strings.Contains(w.content, "-security") && !strings.Contains(w.content, "-updates") {
+		settings.set("upgrade_type", "security")
+	}
+	if m := autoRebootPattern.FindStringSubmatch(w.content); m != nil {
+		if m[1] == "true" {
+			settings.set("reboot", "when-needed")
+		} else {
+			settings.set("reboot", "never")
+		}
+	}
+
+	switch {
+	case enable != nil && !*enable:
+		r.replaceFileWrite(w, prefix+"systemctl disable --now "+r.grammar().automaticTimer)
+	case enable == nil && len(settings) == 0:
+		r.replaceFileWrite(w, "true")
+		r.warn(stmt, "removed %s: its settings have no dnf-automatic counterpart", w.target)
+	default:
+		r.replaceFileWrite(w, r.dnfAutomaticCommand(prefix, settings, enable != nil))
+	}
+	return true
+}
+
+// dnfAutomaticCommand applies settings to the dnf-automatic configuration
+// and, when enable is set, starts its timer. The first such command also
+// installs dnf-automatic.
+func (r *rewriter) dnfAutomaticCommand(prefix string, settings dnfAutomaticSettings, enable bool) string {
+	grammar := r.grammar()
+	var parts []string
+	if !r.dnfAutomaticInstalled {
+		r.dnfAutomaticInstalled = true
+		parts = append(parts, r.target.Install(prefix, []string{grammar.automaticPackage}))
+	}
+	if len(settings) > 0 {
+		if grammar.automaticConfig != "" {
+			parts = append(parts, prefix+grammar.automaticConfig)
+		}
+		parts = append(parts, fmt.Sprintf("%ssed -i %s %s", prefix, strings.Join(settings, " "), dnfAutomaticConf))
+	}
+	if enable {
+		parts = append(parts, prefix+"systemctl enable --now "+grammar.automaticTimer)
+	}
+	return strings.Join(parts, " && ")
+}
+
+// rewriteDpkgReconfigure converts the interactive unattended-upgrades
+// setup, which turns on automatic updates.
+func (r *rewriter) rewriteDpkgReconfigure(cmd command) {
+	for _, arg := range cmd.args {
+		if arg.Lit() == unattendedUpgrades {
+			if !r.requireDNF(cmd) {
+				return
+			}
+			prefix := r.prefixText(cmd)
+			var settings dnfAutomaticSettings
+			settings.set("apply_updates", "yes")
+			r.replace(cmd.call, r.dnfAutomaticCommand(prefix, settings, true))
+			return
+		}
+	}
+}
+
+// rewriteSystemctlUnits points systemctl at the dnf-automatic timer in
+// place of the unattended-upgrades service.
+func (r *rewriter) rewriteSystemctlUnits(cmd command) {
+	for _, arg := range cmd.args {
+		if unit := arg.Lit(); unit == unattendedUpgrades || unit == unattendedUpgrades+".service" {
+			if !r.requireDNF(cmd) {
+				return
+			}
+			r.replace(arg, r.grammar().automaticTimer)
+		}
+	}
+}